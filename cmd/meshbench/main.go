@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+type result struct {
+	scene     string
+	mesher    string
+	cubes     int
+	buildTime time.Duration
+	vertices  int
+	indices   int
+	triangles int
+	meshBytes int
+	allocated uint64
+}
+
+func main() {
+	sceneNames := flag.String("scenes", "solid,noise,terrain,cat", "comma separated scenes to mesh, by name or scene file path")
+	mesherNames := flag.String("meshers", strings.Join(renderer.MesherNames(), ","), "comma separated meshers to run")
+	size := flag.Int("size", 16, "edge length of generated scenes")
+	runs := flag.Int("runs", 10, "number of builds to average over")
+	seed := flag.Int64("seed", 1, "seed for random scenes")
+	format := flag.String("format", "table", "output format: table or csv")
+	flag.Parse()
+
+	var results []result
+	for _, sceneName := range strings.Split(*sceneNames, ",") {
+		cubes, err := loadScene(sceneName, *size, *seed)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, mesherName := range strings.Split(*mesherNames, ",") {
+			mesher, err := renderer.NewMesher(mesherName)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			r := benchmark(mesher, cubes, *runs)
+			r.scene = sceneName
+			r.mesher = mesherName
+			results = append(results, r)
+		}
+	}
+
+	switch *format {
+	case "table":
+		writeTable(os.Stdout, results)
+	case "csv":
+		writeCSV(os.Stdout, results)
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+}
+
+// loadScene generates a named scene, or loads a file in the format
+// scene.Load reads.
+func loadScene(name string, size int, seed int64) ([]primitive.Cube, error) {
+	switch name {
+	case "solid":
+		return scene.Solid(size), nil
+	case "noise":
+		return scene.Noise(size, 0.5, seed), nil
+	case "terrain":
+		return scene.Terrain(size, seed), nil
+	case "cat":
+		return scene.Cat(), nil
+	}
+	if _, err := os.Stat(name); err == nil {
+		return scene.LoadFile(name)
+	}
+	return nil, fmt.Errorf("unknown scene %q: not a generated scene or a file", name)
+}
+
+// benchmark meshes the scene one section at a time, as MeshRenderer does,
+// with a clone of mesher for each section. BorderMeshers also get the cubes
+// around their section.
+func benchmark(mesher renderer.Mesher, cubes []primitive.Cube, runs int) result {
+	if runs < 1 {
+		runs = 1
+	}
+
+	sections, borders := renderer.SectionCubes(cubes)
+	meshers := make([]renderer.Mesher, len(sections))
+	for i := range meshers {
+		meshers[i] = mesher.Clone()
+	}
+	build := func() {
+		for i, m := range meshers {
+			if bm, ok := m.(renderer.BorderMesher); ok {
+				bm.GenerateMeshWithBorder(sections[i], borders[i])
+				continue
+			}
+			m.GenerateMesh(sections[i])
+		}
+	}
+
+	// Warm up once so meshers that reuse buffers are measured in steady state.
+	build()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < runs; i++ {
+		build()
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	r := result{
+		cubes:     len(cubes),
+		buildTime: elapsed / time.Duration(runs),
+		allocated: (after.TotalAlloc - before.TotalAlloc) / uint64(runs),
+	}
+	for _, m := range meshers {
+		vertices, indices := m.GetMesh()
+		vertexCount := len(vertices) / m.VertexLayout().Floats()
+		r.vertices += vertexCount
+		r.indices += len(indices)
+		if len(indices) == 0 {
+			r.triangles += vertexCount / 3
+		} else {
+			r.triangles += len(indices) / 3
+		}
+		r.meshBytes += len(vertices)*4 + len(indices)*4
+	}
+	return r
+}
+
+func writeTable(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scene\tmesher\tcubes\tbuild\tvertices\tindices\ttriangles\tmesh bytes\talloc/op\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\t%d\t%d\t%d\t\n",
+			r.scene, r.mesher, r.cubes, r.buildTime, r.vertices, r.indices, r.triangles, r.meshBytes, r.allocated)
+	}
+	tw.Flush()
+}
+
+func writeCSV(w io.Writer, results []result) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"scene", "mesher", "cubes", "build_ns", "vertices", "indices", "triangles", "mesh_bytes", "alloc_bytes"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.scene,
+			r.mesher,
+			fmt.Sprint(r.cubes),
+			fmt.Sprint(r.buildTime.Nanoseconds()),
+			fmt.Sprint(r.vertices),
+			fmt.Sprint(r.indices),
+			fmt.Sprint(r.triangles),
+			fmt.Sprint(r.meshBytes),
+			fmt.Sprint(r.allocated),
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+func run(t *testing.T, mesherName string, sceneName string, size int) result {
+	t.Helper()
+	cubes, err := loadScene(sceneName, size, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesher, err := renderer.NewMesher(mesherName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return benchmark(mesher, cubes, 1)
+}
+
+// The greedy meshers merge the same faces, so they agree on every scene,
+// including ones larger than a section.
+func TestGreedyMeshersAgree(t *testing.T) {
+	for _, sceneName := range []string{"solid", "noise", "terrain", "cat"} {
+		want := run(t, "greedy", sceneName, 32)
+		for _, mesherName := range []string{"binary-greedy", "packed-greedy"} {
+			got := run(t, mesherName, sceneName, 32)
+			if got.vertices != want.vertices || got.triangles != want.triangles {
+				t.Errorf("%s on %s: %d vertices, %d triangles, greedy has %d, %d",
+					mesherName, sceneName, got.vertices, got.triangles, want.vertices, want.triangles)
+			}
+		}
+	}
+}
+
+// A scene of eight sections meshes to eight times one section, for every
+// mesher, rather than being cut off at the first.
+func TestScenesLargerThanASection(t *testing.T) {
+	for _, mesherName := range renderer.MesherNames() {
+		one := run(t, mesherName, "solid", 16)
+		eight := run(t, mesherName, "solid", 32)
+		if mesherName == "surface-nets" {
+			// Surface nets joins the sections into one surface, so it draws
+			// what the whole scene meshed at once does, not eight boxes.
+			cubes, err := loadScene("solid", 32, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			whole := renderer.NewSurfaceNetsMesher()
+			whole.GenerateMesh(cubes)
+			if _, indices := whole.GetMesh(); eight.triangles != len(indices)/3 {
+				t.Errorf("surface-nets: 32³ in sections has %d triangles, meshed whole %d", eight.triangles, len(indices)/3)
+			}
+			continue
+		}
+		if eight.vertices != 8*one.vertices || eight.triangles != 8*one.triangles {
+			t.Errorf("%s: 32³ has %d vertices, %d triangles, want 8 × %d, %d",
+				mesherName, eight.vertices, eight.triangles, one.vertices, one.triangles)
+		}
+	}
+}
+
+func TestCubeMesherCountsEveryFace(t *testing.T) {
+	r := run(t, "cube", "solid", 32)
+	if r.triangles != 12*32*32*32 {
+		t.Errorf("cube mesher drew %d triangles for 32³ cubes, want 12 per cube", r.triangles)
+	}
+}
+
+func TestLoadSceneFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.txt")
+	if err := os.WriteFile(path, []byte("0 0 0\n0 1 0\n0 2 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cubes, err := loadScene(path, 16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cubes) != 3 {
+		t.Errorf("loaded %d cubes, want 3", len(cubes))
+	}
+	if _, err := loadScene("missing", 16, 1); err == nil {
+		t.Error("an unknown scene that isn't a file should fail")
+	}
+}
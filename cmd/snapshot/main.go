@@ -0,0 +1,96 @@
+// Command snapshot renders a scene to a PNG without opening a window.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func main() {
+	out := flag.String("out", "snapshot.png", "file to write")
+	sceneName := flag.String("scene", "terrain", "scene to render: solid, noise, terrain, pond, shapes or cat")
+	mesher := flag.String("mesher", "greedy", "mesher to build the scene with")
+	size := flag.Int("size", 32, "edge length of generated scenes")
+	seed := flag.Int64("seed", 1, "seed for random scenes")
+	width := flag.Int("width", 800, "image width")
+	height := flag.Int("height", 450, "image height")
+	eye := flag.String("eye", "", "camera position as x,y,z (default looks at the scene from above)")
+	target := flag.String("target", "", "point the camera looks at as x,y,z (default is the scene centre)")
+	software := flag.Bool("software", false, "use the CPU rasterizer even when OpenGL is available")
+	flag.Parse()
+
+	cubes, err := loadScene(*sceneName, *size, *seed)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	half := float32(*size) / 2
+	camera := engine.FixedCamera{
+		Position: mgl32.Vec3{-half, float32(*size) * 1.2, float32(*size) * 1.8},
+		Target:   mgl32.Vec3{half, half / 2, half},
+	}
+	if *eye != "" {
+		if camera.Position, err = parseVec3(*eye); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if *target != "" {
+		if camera.Target, err = parseVec3(*target); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	err = engine.RenderPNG(*out, engine.HeadlessOptions{
+		Width:    *width,
+		Height:   *height,
+		Camera:   camera,
+		Mesher:   *mesher,
+		Cubes:    cubes,
+		Software: *software,
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func loadScene(name string, size int, seed int64) ([]primitive.Cube, error) {
+	switch name {
+	case "solid":
+		return scene.Solid(size), nil
+	case "noise":
+		return scene.Noise(size, 0.5, seed), nil
+	case "terrain":
+		return scene.Terrain(size, seed), nil
+	case "pond":
+		return scene.Pond(size, seed), nil
+	case "shapes":
+		return scene.Shapes(), nil
+	case "cat":
+		return scene.Cat(), nil
+	}
+	return nil, fmt.Errorf("unknown scene %q", name)
+}
+
+func parseVec3(s string) (mgl32.Vec3, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return mgl32.Vec3{}, fmt.Errorf("expected x,y,z, got %q", s)
+	}
+	var v mgl32.Vec3
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return mgl32.Vec3{}, fmt.Errorf("bad coordinate %q: %w", p, err)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
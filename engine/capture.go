@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/capture"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/sirupsen/logrus"
+)
+
+// captureState holds requests from the bus, which are served on the render
+// thread after the next frame is drawn.
+type captureState struct {
+	dir             string
+	screenshot      atomic.Bool
+	toggleRecording atomic.Bool
+	recordOptions   capture.RecordOptions
+	recorder        *capture.Recorder
+}
+
+// SetCaptureDir sets where screenshots and recordings are saved. It
+// defaults to "screenshots".
+func (e *Engine) SetCaptureDir(dir string) {
+	e.capture.dir = dir
+}
+
+// Screenshot saves the next frame as a timestamped PNG. The Screenshot bus
+// topic does the same.
+func (e *Engine) Screenshot() {
+	e.capture.screenshot.Store(true)
+}
+
+// SetRecordOptions configures the next recording. Dir defaults to a
+// timestamped folder in the capture directory.
+func (e *Engine) SetRecordOptions(opts capture.RecordOptions) {
+	e.capture.recordOptions = opts
+}
+
+// ToggleRecording starts or stops recording frames. The ToggleRecording bus
+// topic does the same.
+func (e *Engine) ToggleRecording() {
+	e.capture.toggleRecording.Store(true)
+}
+
+func (e *Engine) captureDir() string {
+	if e.capture.dir == "" {
+		return "screenshots"
+	}
+	return e.capture.dir
+}
+
+// captureFrame reads back the frame just drawn when a screenshot or
+// recording wants it. It runs before the buffers are swapped.
+func (e *Engine) captureFrame() {
+	if e.capture.toggleRecording.Swap(false) {
+		if e.capture.recorder == nil {
+			e.startRecording()
+		} else {
+			e.stopRecording()
+		}
+	}
+
+	screenshot := e.capture.screenshot.Swap(false)
+	recording := e.capture.recorder != nil && e.capture.recorder.Wants()
+	if !screenshot && !recording {
+		return
+	}
+
+	width, height := e.window.GetFramebufferSize()
+	img := renderer.ReadScreen(width, height)
+
+	if screenshot {
+		go func() {
+			path, err := capture.SaveScreenshot(e.captureDir(), img, time.Now())
+			if err != nil {
+				logrus.Errorln("failed to save screenshot:", err)
+				return
+			}
+			logrus.Infoln("saved screenshot", path)
+		}()
+	}
+	if e.capture.recorder != nil {
+		if err := e.capture.recorder.AddFrame(img); err != nil {
+			logrus.Errorln("failed to record frame:", err)
+			e.stopRecording()
+		}
+	}
+}
+
+func (e *Engine) startRecording() {
+	opts := e.capture.recordOptions
+	if opts.Dir == "" {
+		opts.Dir = e.captureDir() + "/recording-" + time.Now().Format("20060102-150405")
+	}
+	recorder, err := capture.NewRecorder(opts)
+	if err != nil {
+		logrus.Errorln("failed to start recording:", err)
+		return
+	}
+	e.capture.recorder = recorder
+	logrus.Infoln("recording to", opts.Dir)
+}
+
+// stopRecording detaches the recorder and finishes it in the background,
+// since the last queued frames may still be encoding.
+func (e *Engine) stopRecording() {
+	if recorder := e.detachRecorder(); recorder != nil {
+		go finishRecording(recorder)
+	}
+}
+
+func (e *Engine) detachRecorder() *capture.Recorder {
+	recorder := e.capture.recorder
+	e.capture.recorder = nil
+	return recorder
+}
+
+func finishRecording(recorder *capture.Recorder) {
+	path, err := recorder.Close()
+	if err != nil {
+		logrus.Errorln("failed to finish recording:", err)
+		return
+	}
+	if dropped := recorder.Dropped(); dropped > 0 {
+		logrus.Warnf("recording dropped %d frames while encoding fell behind", dropped)
+	}
+	logrus.Infof("recorded %d frames to %s", recorder.Frames(), path)
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/daycycle"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+// defaultDayLength is how long a day lasts in game time.
+const defaultDayLength = 20 * time.Minute
+
+// dayNightState ties the world clock to the sky and lighting. The clock
+// always runs; the cycle only changes the look of the world when enabled.
+type dayNightState struct {
+	clock      *daycycle.Clock
+	enabled    atomic.Bool
+	refresh    atomic.Bool
+	lastUpdate time.Time
+	lastMinute int
+}
+
+// Clock is the world clock. It advances with game time while the world is
+// loaded.
+func (e *Engine) Clock() *daycycle.Clock {
+	return e.dayNight.clock
+}
+
+// SetDayNightCycle lets the clock drive the sun, sky, ambient light and
+// stars. When it is off they keep whatever was last set.
+func (e *Engine) SetDayNightCycle(enabled bool) {
+	e.dayNight.enabled.Store(enabled)
+	e.dayNight.refresh.Store(true)
+}
+
+// TimeOfDay is the fraction of the day that has passed since midnight.
+func (e *Engine) TimeOfDay() float32 {
+	return e.dayNight.clock.TimeOfDay()
+}
+
+// SetTimeOfDay jumps the clock to t. The SetTimeOfDay bus topic, with a
+// float32 payload, does the same.
+func (e *Engine) SetTimeOfDay(t float32) {
+	e.dayNight.clock.SetTimeOfDay(t)
+	e.dayNight.refresh.Store(true)
+}
+
+// minutesPerDay is how often the time is published and the world's look
+// updated: once a game minute.
+const minutesPerDay = 24 * 60
+
+// advanceClock runs the clock by the real time since the last frame. Every
+// game minute it publishes a TimeOfDay message with a daycycle.State
+// payload, and applies that state when the cycle is enabled.
+func (e *Engine) advanceClock() {
+	now := time.Now()
+	if !e.dayNight.lastUpdate.IsZero() {
+		e.dayNight.clock.Advance(now.Sub(e.dayNight.lastUpdate))
+	}
+	e.dayNight.lastUpdate = now
+
+	t := e.dayNight.clock.TimeOfDay()
+	minute := int(math.Floor(float64(t * minutesPerDay)))
+	if minute == e.dayNight.lastMinute && !e.dayNight.refresh.Swap(false) {
+		return
+	}
+	e.dayNight.lastMinute = minute
+
+	state := daycycle.At(t)
+	if e.dayNight.enabled.Load() {
+		e.applyDayNight(state)
+	}
+	e.bus.Publish(message.Message{Topic: "TimeOfDay", Requestor: "engine", Payload: state})
+}
+
+func (e *Engine) applyDayNight(state daycycle.State) {
+	e.SetSky(renderer.Sky{
+		Horizon: state.Horizon,
+		Zenith:  state.Zenith,
+		Sun:     state.Sun,
+		Stars:   state.Stars,
+	})
+	e.bus.Publish(message.Message{Topic: "SetSunDirection", Requestor: "engine", Payload: state.LightDirection})
+	e.bus.Publish(message.Message{Topic: "SetSunColor", Requestor: "engine", Payload: state.LightColor})
+	e.bus.Publish(message.Message{Topic: "SetAmbient", Requestor: "engine", Payload: state.Ambient})
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"sync/atomic"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+// debugState is the engine's debug line pass. Chunk borders are toggled
+// with F5 or the ToggleChunkBorders bus topic.
+type debugState struct {
+	lines        *renderer.DebugRenderer
+	chunkBorders atomic.Bool
+}
+
+var chunkBorderColor = component.Color{1, 0.85, 0}
+
+// DebugDraw queues lines drawn over the world, such as boxes, rays and
+// normals. It is safe to use from any goroutine; the DebugDraw bus topic
+// does the same from code that only has the bus.
+func (e *Engine) DebugDraw() *renderer.DebugDraw {
+	return e.debug.lines.Draw()
+}
+
+// ToggleChunkBorders outlines the sections and chunks of every renderer
+// that reports them.
+func (e *Engine) ToggleChunkBorders() {
+	e.debug.chunkBorders.Store(!e.debug.chunkBorders.Load())
+}
+
+// drawDebug runs after the world so the lines are depth tested against it.
+func (e *Engine) drawDebug() {
+	if e.debug.chunkBorders.Load() {
+		draw := e.debug.lines.Draw()
+		for _, r := range e.renderers {
+			if s, ok := r.(renderer.SectionReporter); ok {
+				for _, box := range s.SectionBounds() {
+					draw.DrawAABB(box, chunkBorderColor, 0)
+				}
+			}
+		}
+	}
+	e.debug.lines.Render()
+}
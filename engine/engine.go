@@ -5,10 +5,12 @@ import (
 	"runtime"
 
 	"github.com/dfirebaugh/cube/pkg/camera"
+	"github.com/dfirebaugh/cube/pkg/daycycle"
 	"github.com/dfirebaugh/cube/pkg/input"
 	"github.com/dfirebaugh/cube/pkg/message"
 	"github.com/dfirebaugh/cube/pkg/message/broker"
 	"github.com/dfirebaugh/cube/pkg/player"
+	"github.com/dfirebaugh/cube/pkg/primitive"
 	"github.com/dfirebaugh/cube/renderer"
 	"github.com/sirupsen/logrus"
 
@@ -21,7 +23,13 @@ type Engine struct {
 	window    *glfw.Window
 	camera    *camera.Camera
 	renderers []renderer.Renderer
+	sky       *renderer.SkyRenderer
+	post      *renderer.PostProcessor
 	bus       message.MessageBus
+	capture   captureState
+	dayNight  dayNightState
+	hud       hudState
+	debug     debugState
 }
 
 var worldHasLoaded bool
@@ -45,19 +53,18 @@ func New(startup func()) *Engine {
 	glfw.WindowHint(glfw.ContextVersionMinor, 3)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 
-	monitor := glfw.GetPrimaryMonitor()
-	videoMode := monitor.GetVideoMode()
-
 	windowWidth := 800
 	windowHeight := 450
-	windowX := (videoMode.Width - windowWidth) / 2
-	windowY := (videoMode.Height - windowHeight) / 2
 
 	window, err := glfw.CreateWindow(windowWidth, windowHeight, "cube", nil, nil)
 	if err != nil {
 		log.Fatalln("failed to create window:", err)
 	}
-	window.SetPos(windowX, windowY)
+	// Some setups, such as a virtual framebuffer, report no monitor.
+	if monitor := glfw.GetPrimaryMonitor(); monitor != nil {
+		videoMode := monitor.GetVideoMode()
+		window.SetPos((videoMode.Width-windowWidth)/2, (videoMode.Height-windowHeight)/2)
+	}
 	window.MakeContextCurrent()
 	if err := gl.Init(); err != nil {
 		log.Fatalln("failed to initialize gl:", err)
@@ -71,7 +78,21 @@ func New(startup func()) *Engine {
 		window: window,
 		camera: camera.NewCamera(window),
 		bus:    broker.NewBroker(),
+		sky:    renderer.NewSkyRenderer(),
+		dayNight: dayNightState{
+			clock:      daycycle.NewClock(defaultDayLength),
+			lastMinute: -1,
+		},
 	}
+	engine.sky.SetCamera(engine.camera)
+	engine.sky.SetWindow(window)
+	engine.sky.SetMessageBus(engine.bus)
+	engine.debug.lines = renderer.NewDebugRenderer()
+	engine.debug.lines.SetCamera(engine.camera)
+	engine.debug.lines.SetWindow(window)
+	engine.debug.lines.SetMessageBus(engine.bus)
+	engine.hud.text = renderer.NewTextRenderer()
+	engine.hud.text.SetWindow(window)
 
 	input.Init(window, engine.bus)
 
@@ -79,6 +100,7 @@ func New(startup func()) *Engine {
 
 	engine.camera.ListenForInputEvents(engine.bus)
 	engine.camera.UpdatePosition(-2, 0, 10)
+	engine.camera.SetViewDistance(renderer.DefaultViewDistance)
 
 	go engine.bus.Start()
 	go engine.subscribeToEvents()
@@ -91,6 +113,10 @@ func (e *Engine) framebufferSizeCallback(window *glfw.Window, width int, height
 }
 
 func (e *Engine) close() {
+	// Finish on this goroutine so the recording is complete before exit.
+	if recorder := e.detachRecorder(); recorder != nil {
+		finishRecording(recorder)
+	}
 	e.bus.Stop()
 	glfw.Terminate()
 }
@@ -102,16 +128,64 @@ func (e *Engine) AddRenderer(renderer renderer.Renderer) {
 	e.renderers = append(e.renderers, renderer)
 }
 
+// MessageBus is the bus renderers and input share. Publish to it to drive
+// renderers from outside the render loop.
+func (e *Engine) MessageBus() message.MessageBus {
+	return e.bus
+}
+
+// SetSky changes the sky gradient and the fog colour of every renderer,
+// through the SetSky topic.
+func (e *Engine) SetSky(sky renderer.Sky) {
+	e.sky.SetSky(sky)
+	e.bus.Publish(message.Message{Topic: "SetSky", Requestor: "engine", Payload: sky})
+}
+
+// SetFog changes the fog of every renderer, through the SetFog topic.
+func (e *Engine) SetFog(fog renderer.Fog) {
+	e.bus.Publish(message.Message{Topic: "SetFog", Requestor: "engine", Payload: fog})
+}
+
+// SetViewDistance sets how far every renderer draws, through the camera,
+// and fits linear fog to the new distance. Call SetFog afterwards for other
+// fog.
+func (e *Engine) SetViewDistance(distance float32) {
+	if distance <= 0 {
+		distance = renderer.DefaultViewDistance
+	}
+	e.camera.SetViewDistance(distance)
+	e.SetFog(renderer.FogForViewDistance(renderer.FogLinear, distance))
+}
+
+// SetPostProcessor draws every frame through p. Pass nil to draw straight
+// to the window again.
+func (e *Engine) SetPostProcessor(p *renderer.PostProcessor) {
+	e.post = p
+}
+
+// PostProcessor is the chain set with SetPostProcessor, or nil.
+func (e *Engine) PostProcessor() *renderer.PostProcessor {
+	return e.post
+}
+
+// SetCollision makes the camera collide with the collision boxes of the
+// cubes in blocks, such as a MeshRenderer. Pass nil to fly freely again.
+func (e *Engine) SetCollision(blocks primitive.BlockSource) {
+	e.camera.SetCollision(blocks)
+}
+
 func (e *Engine) applyPhysics() {
 }
 
 func (e *Engine) update() {
 	input.Update(e.window, e.bus)
+	e.camera.ApplyMovement()
 
 	if !worldHasLoaded {
 		return
 	}
 
+	e.advanceClock()
 	e.applyPhysics()
 }
 
@@ -124,15 +198,20 @@ func (e *Engine) SwapBuffers() {
 	glfw.PollEvents()
 }
 
-func (e Engine) ClearScreen() {
+// ClearScreen clears to the sky's horizon colour. The sky is drawn over it
+// by draw.
+func (e *Engine) ClearScreen() {
+	horizon := e.sky.Sky().Horizon
+	gl.ClearColor(horizon[0], horizon[1], horizon[2], 1.0)
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
 }
 
 func (e *Engine) draw() {
+	e.sky.Render()
 	for _, r := range e.renderers {
 		r.Render()
 	}
+	e.drawDebug()
 }
 
 func (e *Engine) Run() {
@@ -140,10 +219,17 @@ func (e *Engine) Run() {
 	for !e.ShouldClose() {
 		e.update()
 
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
+		if e.post != nil {
+			e.post.Begin(e.window.GetFramebufferSize())
+		}
 		e.ClearScreen()
 		e.draw()
+		if e.post != nil {
+			e.post.End()
+		}
+		e.tickHUD()
+		e.drawHUD()
+		e.captureFrame()
 
 		e.SwapBuffers()
 	}
@@ -164,8 +250,23 @@ func (e *Engine) subscribeToEvents() {
 			if !ok {
 				return
 			}
-			if m.GetTopic() == "RequestClose" {
+			switch m.GetTopic() {
+			case "RequestClose":
 				e.window.SetShouldClose(true)
+			case "Screenshot":
+				e.Screenshot()
+			case "ToggleRecording":
+				e.ToggleRecording()
+			case "ToggleHUD":
+				e.ToggleHUD()
+			case "ToggleChunkBorders":
+				e.ToggleChunkBorders()
+			case "SetTimeOfDay":
+				if t, ok := m.GetPayload().(float32); ok {
+					e.SetTimeOfDay(t)
+				} else {
+					logrus.Warnf("ignoring SetTimeOfDay with payload %T", m.GetPayload())
+				}
 			}
 		}
 	}
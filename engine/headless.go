@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/dfirebaugh/cube/pkg/capture"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// FixedCamera is a camera pose that never moves, for rendering single
+// frames.
+type FixedCamera struct {
+	Position mgl32.Vec3
+	Target   mgl32.Vec3
+	Aspect   float32
+}
+
+func (c *FixedCamera) GetViewMatrix() mgl32.Mat4 {
+	return mgl32.LookAtV(c.Position, c.Target, mgl32.Vec3{0, 1, 0})
+}
+
+func (c *FixedCamera) GetProjectionMatrix() mgl32.Mat4 {
+	aspect := c.Aspect
+	if aspect == 0 {
+		aspect = 1
+	}
+	return renderer.Projection(aspect, renderer.DefaultViewDistance)
+}
+
+func (c *FixedCamera) GetPosition() mgl32.Vec3 {
+	return c.Position
+}
+
+func (c *FixedCamera) GetDirection() mgl32.Vec3 {
+	return c.Target.Sub(c.Position).Normalize()
+}
+
+// HeadlessOptions describes one frame rendered without a window.
+type HeadlessOptions struct {
+	Width, Height int
+	Camera        FixedCamera
+	// Mesher is a name from renderer.MesherNames. It defaults to "greedy".
+	Mesher string
+	Cubes  []primitive.Cube
+	// Software skips OpenGL and always uses the CPU rasterizer.
+	Software bool
+}
+
+// RenderImage draws a frame into an offscreen framebuffer using a hidden
+// GLFW context. When no context can be created, such as on a machine
+// without a display, it falls back to renderer.SoftwareRenderer.
+//
+// It initializes and terminates GLFW itself, so don't call it while an
+// Engine is running.
+func RenderImage(opts HeadlessOptions) (*image.RGBA, error) {
+	if opts.Mesher == "" {
+		opts.Mesher = "greedy"
+	}
+	if opts.Camera.Aspect == 0 && opts.Height > 0 {
+		opts.Camera.Aspect = float32(opts.Width) / float32(opts.Height)
+	}
+
+	// An unknown mesher fails the same way on both paths, so report it
+	// before trying OpenGL.
+	mesher, err := renderer.NewMesher(opts.Mesher)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Software {
+		img, err := renderOffscreen(opts, mesher.Clone())
+		if err == nil {
+			return img, nil
+		}
+		logrus.Warnln("falling back to software rendering:", err)
+	}
+	return renderSoftware(opts, mesher), nil
+}
+
+// RenderPNG renders a frame with RenderImage and writes it to path.
+func RenderPNG(path string, opts HeadlessOptions) error {
+	img, err := RenderImage(opts)
+	if err != nil {
+		return err
+	}
+	return capture.WritePNG(path, img)
+}
+
+func renderSoftware(opts HeadlessOptions, mesher renderer.Mesher) *image.RGBA {
+	r := renderer.NewSoftwareRenderer(mesher, opts.Width, opts.Height)
+	r.SetCamera(&opts.Camera)
+	for _, cube := range opts.Cubes {
+		r.AddCube(cube)
+	}
+	r.Render()
+	return r.Image()
+}
+
+func renderOffscreen(opts HeadlessOptions, mesher renderer.Mesher) (img *image.RGBA, err error) {
+	// glfw only logs a missing display from Init and panics on the next
+	// call.
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("no offscreen context: %v", p)
+		}
+	}()
+
+	if err := glfw.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize glfw: %w", err)
+	}
+	defer glfw.Terminate()
+
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	window, err := glfw.CreateWindow(1, 1, "cube", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offscreen context: %w", err)
+	}
+	defer window.Destroy()
+	window.MakeContextCurrent()
+	if err := gl.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize gl: %w", err)
+	}
+
+	framebuffer, err := renderer.NewFramebuffer(opts.Width, opts.Height)
+	if err != nil {
+		return nil, err
+	}
+	defer framebuffer.Delete()
+
+	r := renderer.NewMeshRenderer(mesher)
+	r.SetCamera(&opts.Camera)
+	r.SetWindow(framebuffer)
+	for _, cube := range opts.Cubes {
+		r.AddCube(cube)
+	}
+
+	sky := renderer.NewSkyRenderer()
+	sky.SetCamera(&opts.Camera)
+	sky.SetWindow(framebuffer)
+
+	framebuffer.Bind()
+	defer framebuffer.Unbind()
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	sky.Render()
+	r.Render()
+	return framebuffer.ReadImage(), nil
+}
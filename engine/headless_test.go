@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+func headlessScene() HeadlessOptions {
+	cube := primitive.Cube{Size: 1, Color: component.Color{1, 0, 0}}
+	return HeadlessOptions{
+		Width:    32,
+		Height:   32,
+		Camera:   FixedCamera{Position: mgl32.Vec3{0.5, 0.5, 4}, Target: mgl32.Vec3{0.5, 0.5, 0.5}},
+		Cubes:    []primitive.Cube{cube},
+		Software: true,
+	}
+}
+
+func TestRenderImageSoftware(t *testing.T) {
+	img, err := RenderImage(headlessScene())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 32 || b.Dy() != 32 {
+		t.Fatalf("image is %v, want 32x32", b)
+	}
+	centre, corner := img.RGBAAt(16, 16), img.RGBAAt(0, 0)
+	if centre == corner {
+		t.Fatalf("the cube at the centre looks like the sky: %v", centre)
+	}
+	if centre.R <= centre.G || centre.R <= centre.B {
+		t.Errorf("centre pixel %v isn't the red cube", centre)
+	}
+}
+
+func TestRenderPNGSoftware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame.png")
+	if err := RenderPNG(path, headlessScene()); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, _, _ := img.At(16, 16).RGBA(); r <= g {
+		t.Errorf("centre pixel %v isn't the red cube", img.At(16, 16))
+	}
+}
+
+func TestRenderImageRejectsUnknownMesher(t *testing.T) {
+	opts := headlessScene()
+	opts.Mesher = "no-such-mesher"
+	opts.Software = false
+
+	var log bytes.Buffer
+	logrus.SetOutput(&log)
+	defer logrus.SetOutput(os.Stderr)
+	if _, err := RenderImage(opts); err == nil {
+		t.Error("an unknown mesher rendered")
+	}
+	if strings.Contains(log.String(), "falling back") {
+		t.Errorf("an unknown mesher tried OpenGL first: %s", log.String())
+	}
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// hudState is the debug overlay. It is toggled with F1 or the ToggleHUD
+// bus topic.
+type hudState struct {
+	text      *renderer.TextRenderer
+	enabled   atomic.Bool
+	lastFrame time.Time
+	// frameTime is smoothed so the numbers are readable.
+	frameTime time.Duration
+}
+
+var (
+	hudColor      = mgl32.Vec4{1, 1, 1, 1}
+	hudBackground = mgl32.Vec4{0, 0, 0, 0.5}
+)
+
+// ToggleHUD shows or hides the debug overlay.
+func (e *Engine) ToggleHUD() {
+	e.hud.enabled.Store(!e.hud.enabled.Load())
+}
+
+// HUDEnabled reports whether the debug overlay is showing.
+func (e *Engine) HUDEnabled() bool {
+	return e.hud.enabled.Load()
+}
+
+// tickHUD times the frame. It runs every frame so the numbers are right as
+// soon as the overlay is shown.
+func (e *Engine) tickHUD() {
+	now := time.Now()
+	if !e.hud.lastFrame.IsZero() {
+		frame := now.Sub(e.hud.lastFrame)
+		if e.hud.frameTime == 0 {
+			e.hud.frameTime = frame
+		} else {
+			e.hud.frameTime += (frame - e.hud.frameTime) / 10
+		}
+	}
+	e.hud.lastFrame = now
+}
+
+func (e *Engine) drawHUD() {
+	if !e.hud.enabled.Load() || e.hud.text == nil {
+		return
+	}
+	var metrics []renderer.Metrics
+	for _, r := range e.renderers {
+		if m, ok := r.(renderer.MetricsReporter); ok {
+			metrics = append(metrics, m.Metrics())
+		}
+	}
+	s := strings.Join(hudLines(e.hud.frameTime, e.camera.Position(), e.camera.Direction(), metrics), "\n")
+
+	const margin, padding = 8, 6
+	w, h := e.hud.text.Measure(s)
+	e.hud.text.DrawRect(margin, margin, margin+w+2*padding, margin+h+2*padding, hudBackground)
+	e.hud.text.DrawText(margin+padding, margin+padding, s, hudColor)
+	e.hud.text.Render()
+}
+
+// hudLines formats the overlay: frame rate, camera and the renderers'
+// metrics summed together.
+func hudLines(frameTime time.Duration, position, direction mgl32.Vec3, metrics []renderer.Metrics) []string {
+	fps := 0.0
+	if frameTime > 0 {
+		fps = float64(time.Second) / float64(frameTime)
+	}
+
+	var total renderer.Metrics
+	var meshers []string
+	for _, m := range metrics {
+		total.Vertices += m.Vertices
+		total.Triangles += m.Triangles
+		total.Sections += m.Sections
+		total.Cull.Visible += m.Cull.Visible
+		total.Cull.Culled += m.Cull.Culled
+		total.Cull.Occluded += m.Cull.Occluded
+		if m.Mesher != "" && !slices.Contains(meshers, m.Mesher) {
+			meshers = append(meshers, m.Mesher)
+		}
+	}
+	mesher := "none"
+	if len(meshers) > 0 {
+		mesher = strings.Join(meshers, ", ")
+	}
+
+	return []string{
+		fmt.Sprintf("fps %.0f (%.2f ms)", fps, float64(frameTime)/float64(time.Millisecond)),
+		fmt.Sprintf("pos %.1f %.1f %.1f", position[0], position[1], position[2]),
+		fmt.Sprintf("dir %.2f %.2f %.2f", direction[0], direction[1], direction[2]),
+		fmt.Sprintf("vertices %d triangles %d", total.Vertices, total.Triangles),
+		fmt.Sprintf("chunks %d visible %d culled %d occluded %d",
+			total.Sections, total.Cull.Visible, total.Cull.Culled, total.Cull.Occluded),
+		"mesher " + mesher,
+	}
+}
@@ -7,9 +7,9 @@ require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240506104042-037f3cc74f2a
 	github.com/go-gl/mathgl v1.1.0
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f
 )
 
 require (
-	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f // indirect
 	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
 )
@@ -15,8 +15,30 @@ var (
 	pink   uint32
 
 	texturesLoaded bool
+
+	textureArray       uint32
+	textureArrayLoaded bool
+)
+
+// Layers of the texture array returned by TextureArray.
+const (
+	RedLayer uint32 = iota
+	GreenLayer
+	BlueLayer
+	YellowLayer
+	GreyLayer
+	PinkLayer
 )
 
+var textureFiles = []string{
+	"assets/textures/red.png",
+	"assets/textures/green.png",
+	"assets/textures/blue.png",
+	"assets/textures/yellow.png",
+	"assets/textures/grey.png",
+	"assets/textures/pink.png",
+}
+
 func loadTextures() {
 	var err error
 	red, err = renderer.LoadTexture("assets/textures/red.png")
@@ -47,6 +69,22 @@ func loadTextures() {
 	texturesLoaded = true
 }
 
+// TextureArray loads every block texture into one texture array, indexed by
+// the *Layer constants.
+func TextureArray() uint32 {
+	if textureArrayLoaded {
+		return textureArray
+	}
+
+	var err error
+	textureArray, err = renderer.LoadTextureArray(textureFiles...)
+	if err != nil {
+		logrus.Fatalln("failed to load texture array:", err)
+	}
+	textureArrayLoaded = true
+	return textureArray
+}
+
 func TestBlock() primitive.Cube {
 	if !texturesLoaded {
 		loadTextures()
@@ -61,6 +99,14 @@ func TestBlock() primitive.Cube {
 			Top:    grey,
 			Bottom: pink,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  RedLayer,
+			Back:   GreenLayer,
+			Left:   BlueLayer,
+			Right:  YellowLayer,
+			Top:    GreyLayer,
+			Bottom: PinkLayer,
+		},
 	}
 }
 
@@ -78,6 +124,14 @@ func PinkBlock() primitive.Cube {
 			Top:    pink,
 			Bottom: pink,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  PinkLayer,
+			Back:   PinkLayer,
+			Left:   PinkLayer,
+			Right:  PinkLayer,
+			Top:    PinkLayer,
+			Bottom: PinkLayer,
+		},
 	}
 }
 
@@ -95,6 +149,14 @@ func RedBlock() primitive.Cube {
 			Top:    red,
 			Bottom: red,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  RedLayer,
+			Back:   RedLayer,
+			Left:   RedLayer,
+			Right:  RedLayer,
+			Top:    RedLayer,
+			Bottom: RedLayer,
+		},
 	}
 }
 
@@ -112,6 +174,14 @@ func BlueBlock() primitive.Cube {
 			Top:    blue,
 			Bottom: blue,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  BlueLayer,
+			Back:   BlueLayer,
+			Left:   BlueLayer,
+			Right:  BlueLayer,
+			Top:    BlueLayer,
+			Bottom: BlueLayer,
+		},
 	}
 }
 
@@ -129,6 +199,14 @@ func GreenBlock() primitive.Cube {
 			Top:    green,
 			Bottom: green,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  GreenLayer,
+			Back:   GreenLayer,
+			Left:   GreenLayer,
+			Right:  GreenLayer,
+			Top:    GreenLayer,
+			Bottom: GreenLayer,
+		},
 	}
 }
 
@@ -146,6 +224,14 @@ func YellowBlock() primitive.Cube {
 			Top:    yellow,
 			Bottom: yellow,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  YellowLayer,
+			Back:   YellowLayer,
+			Left:   YellowLayer,
+			Right:  YellowLayer,
+			Top:    YellowLayer,
+			Bottom: YellowLayer,
+		},
 	}
 }
 
@@ -163,5 +249,13 @@ func GreyBlock() primitive.Cube {
 			Top:    grey,
 			Bottom: grey,
 		},
+		Layers: primitive.CubeLayers{
+			Front:  GreyLayer,
+			Back:   GreyLayer,
+			Left:   GreyLayer,
+			Right:  GreyLayer,
+			Top:    GreyLayer,
+			Bottom: GreyLayer,
+		},
 	}
 }
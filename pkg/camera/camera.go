@@ -2,8 +2,10 @@ package camera
 
 import (
 	"math"
+	"sync"
 
 	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/primitive"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/sirupsen/logrus"
@@ -30,11 +32,20 @@ type Camera struct {
 	up        mgl32.Vec3
 	right     mgl32.Vec3
 	fov       float32
+	far       float32
 	Yaw       float32
 	Pitch     float32
 	Distance  float32
+
+	// moveMu guards blocks and pending, which input goroutines write.
+	moveMu  sync.Mutex
+	blocks  primitive.BlockSource
+	pending mgl32.Vec3
 }
 
+// collisionRadius is half the width of the box the camera collides as.
+const collisionRadius = 0.25
+
 func NewCamera(window *glfw.Window) *Camera {
 	return &Camera{
 		window:    window,
@@ -70,13 +81,54 @@ func (c *Camera) UpdatePosition(dx, dy, dz float32) {
 }
 
 func (c *Camera) Move(dx, dy, dz float32) {
+	move := mgl32.Vec3{dx, dy, dz}
 	if Mode == FirstPerson {
-		c.position = c.position.Add(c.direction.Mul(dz))
-		c.position = c.position.Add(c.right.Mul(dx))
-		c.position = c.position.Add(c.up.Mul(dy))
-	} else {
-		c.UpdatePosition(dx, dy, dz)
+		move = c.direction.Mul(dz).Add(c.right.Mul(dx)).Add(c.up.Mul(dy))
+	}
+
+	c.moveMu.Lock()
+	defer c.moveMu.Unlock()
+	if c.blocks == nil {
+		c.position = c.position.Add(move)
+		return
+	}
+	c.pending = c.pending.Add(move)
+}
+
+// SetCollision makes moves slide along the collision boxes of the cubes in
+// blocks instead of passing through them. Moves then wait for ApplyMovement.
+// Pass nil to fly freely again.
+func (c *Camera) SetCollision(blocks primitive.BlockSource) {
+	c.moveMu.Lock()
+	defer c.moveMu.Unlock()
+	c.blocks = blocks
+	c.position = c.position.Add(c.pending)
+	c.pending = mgl32.Vec3{}
+}
+
+// ApplyMovement makes the moves since the last call, sliding along the
+// blocks set with SetCollision. Call it once a frame on the thread that
+// changes the blocks.
+func (c *Camera) ApplyMovement() {
+	c.moveMu.Lock()
+	blocks, move := c.blocks, c.pending
+	c.pending = mgl32.Vec3{}
+	c.moveMu.Unlock()
+	if blocks == nil || move == (mgl32.Vec3{}) {
+		return
+	}
+
+	// A camera that starts inside a block moves freely until it's out.
+	if box := c.box(); !primitive.CollidesWith(blocks, box) {
+		move = primitive.Slide(blocks, box, move)
 	}
+	c.position = c.position.Add(move)
+}
+
+// box is the space the camera takes up when it collides.
+func (c *Camera) box() primitive.AABB {
+	r := mgl32.Vec3{collisionRadius, collisionRadius, collisionRadius}
+	return primitive.AABB{Min: c.position.Sub(r), Max: c.position.Add(r)}
 }
 
 func (c *Camera) updateOrbit(x, y, z float32) {
@@ -111,7 +163,19 @@ func (c *Camera) GetProjectionMatrix() mgl32.Mat4 {
 	windowWidth, windowHeight := c.window.GetSize()
 	aspectRatio := float32(windowWidth) / float32(windowHeight)
 	logrus.Infof("Window size: (%d, %d), Aspect ratio: %f", windowWidth, windowHeight, aspectRatio)
-	return mgl32.Perspective(mgl32.DegToRad(c.fov), aspectRatio, 0.1, 100.0)
+	return mgl32.Perspective(mgl32.DegToRad(c.fov), aspectRatio, 0.1, c.far)
+}
+
+// SetViewDistance sets the far plane of the projection matrix, which
+// renderers draw to.
+func (c *Camera) SetViewDistance(distance float32) {
+	c.far = distance
+}
+
+// ViewDistance is the far plane set with SetViewDistance, or zero before it
+// is set.
+func (c *Camera) ViewDistance() float32 {
+	return c.far
 }
 
 func (c *Camera) ProcessMouseMovement(xOffset, yOffset float32, constrainPitch bool) {
@@ -0,0 +1,44 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testBlocks map[[3]int]primitive.Cube
+
+func (b testBlocks) BlockAt(x, y, z int) (primitive.Cube, bool) {
+	cube, ok := b[[3]int{x, y, z}]
+	return cube, ok
+}
+
+func TestMoveSlidesAlongBlocks(t *testing.T) {
+	wall := primitive.Cube{Size: 1}
+	wall.X = 2
+	c := NewCamera(nil)
+	c.SetPosition(1, 0.5, 0.5)
+	c.SetCollision(testBlocks{{2, 0, 0}: wall})
+	Mode = Custom
+	defer func() { Mode = FirstPerson }()
+
+	// Moves wait for ApplyMovement once there is something to collide with.
+	c.Move(1, 0, 0.5)
+	if got := c.GetPosition(); got != (mgl32.Vec3{1, 0.5, 0.5}) {
+		t.Fatalf("camera moved to %v before ApplyMovement", got)
+	}
+
+	// The wall stops the move along x, and the camera slides along z.
+	c.ApplyMovement()
+	if got := c.GetPosition(); got != (mgl32.Vec3{1, 0.5, 1}) {
+		t.Errorf("camera moved to %v, want it to stop at the wall and slide to 1, 0.5, 1", got)
+	}
+
+	// Without collision, moves happen straight away.
+	c.SetCollision(nil)
+	c.Move(1, 0, 0)
+	if got := c.GetPosition(); got != (mgl32.Vec3{2, 0.5, 1}) {
+		t.Errorf("camera moved to %v, want 2, 0.5, 1", got)
+	}
+}
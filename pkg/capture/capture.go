@@ -0,0 +1,252 @@
+// Package capture saves rendered frames as screenshots and recordings.
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SaveScreenshot writes img to dir as a PNG named after t and returns the
+// path.
+func SaveScreenshot(dir string, img image.Image, t time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "cube-"+t.Format("20060102-150405.000")+".png")
+	return path, WritePNG(path, img)
+}
+
+// WritePNG encodes img as a PNG file at path.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type Format int
+
+const (
+	// PNG writes each kept frame to its own numbered file.
+	PNG Format = iota
+	// GIF collects kept frames into animations of up to GIFFrames frames.
+	GIF
+)
+
+// RecordOptions configures a Recorder.
+type RecordOptions struct {
+	Dir    string
+	Format Format
+	// Every keeps one frame in this many. Zero or one keeps them all.
+	Every int
+	// FrameRate is the rate frames are offered at, used to time GIF frames.
+	// It defaults to 60.
+	FrameRate int
+	// QueueSize is how many kept frames can wait to be written. Frames kept
+	// while the queue is full are dropped. It defaults to 16.
+	QueueSize int
+	// GIFFrames splits a GIF recording into files of at most this many
+	// frames, so a long recording isn't held in memory. It defaults to 150.
+	GIFFrames int
+}
+
+// Recorder keeps every Nth frame it is given. Kept frames are encoded and
+// written on a separate goroutine, so AddFrame doesn't wait for them.
+type Recorder struct {
+	opts    RecordOptions
+	offered int
+	kept    int
+	dropped int
+	frames  chan image.Image
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+
+	// Owned by the writer goroutine.
+	written int
+	anim    gif.GIF
+	parts   []string
+}
+
+func NewRecorder(opts RecordOptions) (*Recorder, error) {
+	if opts.Every < 1 {
+		opts.Every = 1
+	}
+	if opts.FrameRate < 1 {
+		opts.FrameRate = 60
+	}
+	if opts.QueueSize < 1 {
+		opts.QueueSize = 16
+	}
+	if opts.GIFFrames < 1 {
+		opts.GIFFrames = 150
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		opts:   opts,
+		frames: make(chan image.Image, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go r.write()
+	return r, nil
+}
+
+// Wants reports whether the next frame will be kept, so callers can skip
+// reading it back otherwise.
+func (r *Recorder) Wants() bool {
+	return r.offered%r.opts.Every == 0
+}
+
+// AddFrame offers a frame. Frames between every Nth are dropped. A kept
+// frame is owned by the recorder and must not be changed afterwards. The
+// error is the first one the writer hit, if any.
+func (r *Recorder) AddFrame(img image.Image) error {
+	keep := r.Wants()
+	r.offered++
+	if !keep {
+		return nil
+	}
+	if err := r.failed(); err != nil {
+		return err
+	}
+
+	select {
+	case r.frames <- img:
+		r.kept++
+	default:
+		r.dropped++
+	}
+	return nil
+}
+
+// Frames is the number of frames kept so far.
+func (r *Recorder) Frames() int {
+	return r.kept
+}
+
+// Dropped is the number of frames that would have been kept but arrived
+// while the queue was full.
+func (r *Recorder) Dropped() int {
+	return r.dropped
+}
+
+func (r *Recorder) failed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// write encodes queued frames until the queue is closed.
+func (r *Recorder) write() {
+	defer close(r.done)
+	for img := range r.frames {
+		if r.failed() != nil {
+			continue
+		}
+		if err := r.writeFrame(img); err != nil {
+			r.fail(err)
+		}
+	}
+	if r.failed() == nil && len(r.anim.Image) > 0 {
+		if err := r.writeGIF(); err != nil {
+			r.fail(err)
+		}
+	}
+}
+
+func (r *Recorder) writeFrame(img image.Image) error {
+	r.written++
+	if r.opts.Format != GIF {
+		return WritePNG(r.framePath(r.written, "png"), img)
+	}
+
+	r.anim.Image = append(r.anim.Image, toPaletted(img))
+	r.anim.Delay = append(r.anim.Delay, r.frameDelay())
+	if len(r.anim.Image) < r.opts.GIFFrames {
+		return nil
+	}
+	return r.writeGIF()
+}
+
+// writeGIF writes the frames collected so far as the next part of the
+// recording.
+func (r *Recorder) writeGIF() error {
+	path := filepath.Join(r.opts.Dir, fmt.Sprintf("recording_%03d.gif", len(r.parts)+1))
+	anim := r.anim
+	r.anim = gif.GIF{}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, &anim); err != nil {
+		f.Close()
+		return err
+	}
+	r.parts = append(r.parts, path)
+	return f.Close()
+}
+
+// frameDelay is the time between kept frames in hundredths of a second.
+func (r *Recorder) frameDelay() int {
+	delay := r.opts.Every * 100 / r.opts.FrameRate
+	if delay < 2 {
+		// Most viewers treat shorter delays as 10.
+		delay = 2
+	}
+	return delay
+}
+
+func (r *Recorder) framePath(n int, ext string) string {
+	return filepath.Join(r.opts.Dir, fmt.Sprintf("frame_%05d.%s", n, ext))
+}
+
+// Close waits for queued frames to be written and finishes the recording.
+// It returns the GIF's path when the recording is one GIF, and the
+// directory otherwise. Close blocks while frames are encoded, so callers on
+// the render thread should run it on another goroutine.
+func (r *Recorder) Close() (string, error) {
+	close(r.frames)
+	<-r.done
+	if err := r.failed(); err != nil {
+		return "", err
+	}
+	if r.opts.Format == GIF && len(r.parts) < 2 {
+		if len(r.parts) == 0 {
+			return "", nil
+		}
+		return r.parts[0], nil
+	}
+	return r.opts.Dir, nil
+}
+
+func toPaletted(img image.Image) *image.Paletted {
+	bounds := img.Bounds()
+	p := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(p, bounds, img, bounds.Min)
+	return p
+}
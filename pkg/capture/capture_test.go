@@ -0,0 +1,157 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func frame(c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	path, err := SaveScreenshot(dir, frame(color.RGBA{255, 0, 0, 255}), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "cube-20240506-070809.000.png"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderKeepsEveryNthFrame(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(RecordOptions{Dir: dir, Every: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := r.AddFrame(frame(color.RGBA{uint8(i), 0, 0, 255})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Frames 0, 3 and 6.
+	if r.Frames() != 3 {
+		t.Errorf("Frames() = %d, want 3", r.Frames())
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "frame_*.png"))
+	if len(files) != 3 {
+		t.Errorf("wrote %d files, want 3: %v", len(files), files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "frame_00003.png")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderGIF(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(RecordOptions{Dir: dir, Format: GIF, Every: 6, FrameRate: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		if err := r.AddFrame(frame(color.RGBA{0, uint8(i * 20), 0, 255})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path, err := r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 2 {
+		t.Errorf("GIF has %d frames, want 2", len(anim.Image))
+	}
+	if anim.Delay[0] != 10 {
+		t.Errorf("delay = %d, want 10", anim.Delay[0])
+	}
+}
+
+func TestRecorderSplitsLongGIFs(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(RecordOptions{Dir: dir, Format: GIF, GIFFrames: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := r.AddFrame(frame(color.RGBA{0, 0, uint8(i * 40), 255})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path, err := r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != dir {
+		t.Errorf("Close() = %q, want the directory %q", path, dir)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "recording_*.gif"))
+	if len(files) != 3 {
+		t.Fatalf("wrote %d GIFs, want 3: %v", len(files), files)
+	}
+	total := 0
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anim, err := gif.DecodeAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(anim.Image) > 2 {
+			t.Errorf("%s has %d frames, want at most 2", file, len(anim.Image))
+		}
+		total += len(anim.Image)
+	}
+	if total != 5 {
+		t.Errorf("GIFs hold %d frames, want 5", total)
+	}
+}
+
+func TestRecorderReportsWriteErrors(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(RecordOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Frames can't be written once the directory is gone.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddFrame(frame(color.RGBA{255, 255, 255, 255})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Close(); err == nil {
+		t.Error("Close() succeeded after a frame failed to write")
+	}
+}
@@ -0,0 +1,208 @@
+// Package daycycle keeps the time of day and works out the sun, sky and
+// light that go with it.
+package daycycle
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Times of day as fractions of a day, starting at midnight.
+const (
+	Midnight float32 = 0
+	Sunrise  float32 = 0.25
+	Noon     float32 = 0.5
+	Sunset   float32 = 0.75
+)
+
+// Clock is a world clock measured in days. It is safe to use from several
+// goroutines.
+type Clock struct {
+	mu        sync.Mutex
+	dayLength time.Duration
+	time      float64
+	paused    bool
+}
+
+// NewClock starts a clock in the morning whose days last dayLength of game
+// time.
+func NewClock(dayLength time.Duration) *Clock {
+	return &Clock{dayLength: dayLength, time: 0.3}
+}
+
+// Advance moves the clock on by dt of game time unless it is paused.
+func (c *Clock) Advance(dt time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused || c.dayLength <= 0 {
+		return
+	}
+	c.time += float64(dt) / float64(c.dayLength)
+}
+
+// TimeOfDay is the fraction of the current day that has passed, from 0 at
+// midnight up to 1.
+func (c *Clock) TimeOfDay() float32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, frac := math.Modf(c.time)
+	return float32(frac)
+}
+
+// SetTimeOfDay jumps to t within the current day. t wraps, so 1.25 is
+// sunrise.
+func (c *Clock) SetTimeOfDay(t float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	day := math.Floor(c.time)
+	c.time = day + float64(t) - math.Floor(float64(t))
+}
+
+// Day counts the whole days that have passed.
+func (c *Clock) Day() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int(math.Floor(c.time))
+}
+
+func (c *Clock) DayLength() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dayLength
+}
+
+func (c *Clock) SetDayLength(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dayLength = d
+}
+
+func (c *Clock) SetPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+}
+
+func (c *Clock) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Hour returns t on a 24 hour clock.
+func Hour(t float32) float32 {
+	return t * 24
+}
+
+// State is how the world looks at a time of day.
+type State struct {
+	TimeOfDay float32
+	// Sun points from the world towards the sun.
+	Sun mgl32.Vec3
+	// LightDirection is the direction light travels from the sun by day
+	// and the moon by night, with LightColor fading to black as either
+	// crosses the horizon.
+	LightDirection mgl32.Vec3
+	LightColor     component.Color
+	Ambient        float32
+	Horizon        component.Color
+	Zenith         component.Color
+	// Stars is how visible the stars are, from 0 by day to 1 at night.
+	Stars float32
+}
+
+// IsNight reports whether the sun is below the horizon.
+func (s State) IsNight() bool {
+	return s.Sun[1] < 0
+}
+
+// sunTilt leans the sun's path away from straight overhead so shadows
+// don't line up with the block grid.
+const sunTilt = 0.4
+
+// SunAt points towards the sun at time of day t. It rises in the east (+X)
+// at Sunrise and is highest at Noon.
+func SunAt(t float32) mgl32.Vec3 {
+	angle := 2 * math.Pi * float64(t-Sunrise)
+	return mgl32.Vec3{float32(math.Cos(angle)), float32(math.Sin(angle)), sunTilt}.Normalize()
+}
+
+// keyframe is the look of the world at a sun height.
+type keyframe struct {
+	height   float32
+	horizon  component.Color
+	zenith   component.Color
+	sunColor component.Color
+	ambient  float32
+}
+
+// keyframes are ordered by the sun's height, the y of SunAt.
+var keyframes = []keyframe{
+	{-0.3, component.Color{0.02, 0.03, 0.08}, component.Color{0, 0.01, 0.04}, component.Color{0.25, 0.3, 0.45}, 0.1},
+	{-0.05, component.Color{0.2, 0.15, 0.25}, component.Color{0.05, 0.07, 0.18}, component.Color{0.25, 0.3, 0.45}, 0.15},
+	{0.05, component.Color{0.95, 0.55, 0.3}, component.Color{0.25, 0.3, 0.55}, component.Color{1, 0.6, 0.35}, 0.25},
+	{0.35, component.Color{0.72, 0.82, 0.9}, component.Color{0.28, 0.48, 0.82}, component.Color{1, 1, 1}, 0.35},
+}
+
+// At works out the world's look at time of day t.
+func At(t float32) State {
+	t -= float32(math.Floor(float64(t)))
+	sun := SunAt(t)
+	height := sun[1]
+	k := interpolate(height)
+
+	// The moon is opposite the sun. Either light fades out near the
+	// horizon so the switch between them isn't seen.
+	direction := sun.Mul(-1)
+	if height < 0 {
+		direction = sun
+	}
+	fade := smoothstep(0, 0.1, float32(math.Abs(float64(height))))
+
+	return State{
+		TimeOfDay:      t,
+		Sun:            sun,
+		LightDirection: direction,
+		LightColor:     component.Color(mgl32.Vec3(k.sunColor).Mul(fade)),
+		Ambient:        k.ambient,
+		Horizon:        k.horizon,
+		Zenith:         k.zenith,
+		Stars:          smoothstep(0, -0.25, height),
+	}
+}
+
+func interpolate(height float32) keyframe {
+	if height <= keyframes[0].height {
+		return keyframes[0]
+	}
+	for i := 1; i < len(keyframes); i++ {
+		a, b := keyframes[i-1], keyframes[i]
+		if height > b.height {
+			continue
+		}
+		f := (height - a.height) / (b.height - a.height)
+		return keyframe{
+			height:   height,
+			horizon:  lerpColor(a.horizon, b.horizon, f),
+			zenith:   lerpColor(a.zenith, b.zenith, f),
+			sunColor: lerpColor(a.sunColor, b.sunColor, f),
+			ambient:  a.ambient + (b.ambient-a.ambient)*f,
+		}
+	}
+	return keyframes[len(keyframes)-1]
+}
+
+func lerpColor(a, b component.Color, f float32) component.Color {
+	return component.Color(mgl32.Vec3(a).Mul(1 - f).Add(mgl32.Vec3(b).Mul(f)))
+}
+
+// smoothstep matches GLSL's, including edge0 > edge1.
+func smoothstep(edge0, edge1, x float32) float32 {
+	t := (x - edge0) / (edge1 - edge0)
+	t = float32(math.Min(math.Max(float64(t), 0), 1))
+	return t * t * (3 - 2*t)
+}
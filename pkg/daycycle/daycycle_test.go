@@ -0,0 +1,92 @@
+package daycycle
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClockAdvancesAndWraps(t *testing.T) {
+	c := NewClock(time.Minute)
+	c.SetTimeOfDay(0.9)
+	c.Advance(12 * time.Second)
+	if got := c.TimeOfDay(); math.Abs(float64(got-0.1)) > 1e-4 {
+		t.Errorf("TimeOfDay = %v, want 0.1", got)
+	}
+	if got := c.Day(); got != 1 {
+		t.Errorf("Day = %d, want 1", got)
+	}
+
+	c.SetPaused(true)
+	c.Advance(time.Hour)
+	if got := c.TimeOfDay(); math.Abs(float64(got-0.1)) > 1e-4 {
+		t.Errorf("paused clock moved to %v", got)
+	}
+}
+
+func TestSetTimeOfDayWraps(t *testing.T) {
+	c := NewClock(time.Minute)
+	c.SetTimeOfDay(1.25)
+	if got := c.TimeOfDay(); got != Sunrise {
+		t.Errorf("SetTimeOfDay(1.25) gave %v, want sunrise", got)
+	}
+	c.SetTimeOfDay(-0.25)
+	if got := c.TimeOfDay(); got != Sunset {
+		t.Errorf("SetTimeOfDay(-0.25) gave %v, want sunset", got)
+	}
+}
+
+func TestSunPath(t *testing.T) {
+	if y := SunAt(Noon)[1]; y < 0.9 {
+		t.Errorf("sun height at noon = %v, want near overhead", y)
+	}
+	if y := SunAt(Midnight)[1]; y > -0.9 {
+		t.Errorf("sun height at midnight = %v, want far below", y)
+	}
+	for _, tod := range []float32{Sunrise, Sunset} {
+		if y := SunAt(tod)[1]; math.Abs(float64(y)) > 1e-5 {
+			t.Errorf("sun height at %v = %v, want the horizon", tod, y)
+		}
+	}
+	if x := SunAt(Sunrise)[0]; x <= 0 {
+		t.Errorf("sun rises at x = %v, want the east", x)
+	}
+}
+
+func TestDayAndNight(t *testing.T) {
+	noon, midnight := At(Noon), At(Midnight)
+	if noon.IsNight() || !midnight.IsNight() {
+		t.Fatal("noon should be day and midnight night")
+	}
+	if noon.Stars != 0 || midnight.Stars != 1 {
+		t.Errorf("stars = %v at noon and %v at midnight", noon.Stars, midnight.Stars)
+	}
+	if noon.Ambient <= midnight.Ambient {
+		t.Errorf("ambient at noon %v isn't above midnight %v", noon.Ambient, midnight.Ambient)
+	}
+	// Light always comes from above.
+	for _, s := range []State{noon, midnight} {
+		if s.LightDirection[1] >= 0 {
+			t.Errorf("light at %v travels upwards: %v", s.TimeOfDay, s.LightDirection)
+		}
+	}
+}
+
+func TestLightFadesAcrossTheHorizon(t *testing.T) {
+	// The switch from sun to moon happens in the dark.
+	for _, tod := range []float32{Sunrise, Sunset} {
+		if c := At(tod).LightColor; c[0]+c[1]+c[2] > 1e-4 {
+			t.Errorf("light at %v = %v, want black", tod, c)
+		}
+	}
+	// And nothing jumps between nearby times.
+	for tod := float32(0); tod < 1; tod += 0.0005 {
+		a, b := At(tod), At(tod+0.0005)
+		if d := a.LightColor[0] - b.LightColor[0]; math.Abs(float64(d)) > 0.1 {
+			t.Fatalf("light jumps by %v at %v", d, tod)
+		}
+		if d := a.Horizon[0] - b.Horizon[0]; math.Abs(float64(d)) > 0.1 {
+			t.Fatalf("horizon jumps by %v at %v", d, tod)
+		}
+	}
+}
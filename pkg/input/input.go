@@ -33,6 +33,7 @@ func Update(window *glfw.Window, broker message.MessageBus) {
 		handleMouseMovement(window, broker)
 	}
 	toggleWireframeMode(window, broker)
+	handleFunctionKeys(window, broker)
 	handleClose(window, broker)
 
 	updateKeyStates(window)
@@ -122,18 +123,22 @@ func toggleWireframeMode(window *glfw.Window, broker message.MessageBus) {
 		return
 	}
 
-	event := message.Message{
-		Topic:     "ToggleWireframe",
-		Requestor: "input",
-		Payload:   nil,
-	}
+	logrus.Traceln("Publishing ToggleWireframe event")
+	publishKeyEvent(broker, "ToggleWireframe")
+}
+
+// publishKeyEvent publishes a topic without a payload. The broker drops a
+// message identical to the one before it, so a TestEvent goes first to let
+// the same key work twice in a row.
+func publishKeyEvent(broker message.MessageBus, topic string) {
 	broker.Publish(message.Message{
 		Topic:     "TestEvent",
 		Requestor: "input",
-		Payload:   nil,
 	})
-	logrus.Traceln("Publishing ToggleWireframe event")
-	broker.Publish(event)
+	broker.Publish(message.Message{
+		Topic:     topic,
+		Requestor: "input",
+	})
 }
 
 func handleClose(window *glfw.Window, broker message.MessageBus) {
@@ -147,6 +152,27 @@ func handleClose(window *glfw.Window, broker message.MessageBus) {
 	})
 }
 
+// functionKeys are the topics published by the function keys.
+var functionKeys = []struct {
+	key   glfw.Key
+	topic string
+}{
+	{glfw.KeyF1, "ToggleHUD"},
+	{glfw.KeyF2, "Screenshot"},
+	{glfw.KeyF3, "ToggleRecording"},
+	{glfw.KeyF4, "ToggleShadows"},
+	{glfw.KeyF5, "ToggleChunkBorders"},
+	{glfw.KeyF6, "ToggleSelection"},
+}
+
+func handleFunctionKeys(window *glfw.Window, broker message.MessageBus) {
+	for _, k := range functionKeys {
+		if IsButtonJustPressed(window, k.key) {
+			publishKeyEvent(broker, k.topic)
+		}
+	}
+}
+
 func handleVerticalMovement(window *glfw.Window, broker message.MessageBus, zIncrement float32) {
 	if window.GetKey(glfw.KeySpace) == glfw.Press {
 		broker.Publish(message.Message{
@@ -0,0 +1,196 @@
+// Package occlusion finds which sections of a world can be seen from a
+// camera, in the style of Minecraft's advanced occlusion culling.
+//
+// Each section records which pairs of its faces are joined through open
+// cells. A breadth first search from the camera's section only crosses a
+// section from one face to another when they are joined, so caves and
+// enclosed rooms behind solid ground are never reached.
+package occlusion
+
+// Face is one side of a section.
+type Face uint8
+
+const (
+	NegX Face = iota
+	PosX
+	NegY
+	PosY
+	NegZ
+	PosZ
+)
+
+const faceCount = 6
+
+// Opposite is the face on the other side of the section.
+func (f Face) Opposite() Face {
+	return f ^ 1
+}
+
+// step is the section offset reached by leaving through a face.
+func (f Face) step() Key {
+	var k Key
+	if f%2 == 0 {
+		k[f/2] = -1
+	} else {
+		k[f/2] = 1
+	}
+	return k
+}
+
+// Visibility is a set of face pairs that are joined through open cells.
+type Visibility uint64
+
+// AllVisible joins every face to every other, as in an empty section.
+const AllVisible Visibility = 1<<(faceCount*faceCount) - 1
+
+func (v Visibility) Connects(a, b Face) bool {
+	return v&(1<<(a*faceCount+b)) != 0
+}
+
+func (v *Visibility) connect(a, b Face) {
+	*v |= 1<<(a*faceCount+b) | 1<<(b*faceCount+a)
+}
+
+// ComputeVisibility flood fills the open cells of a size³ section. solid
+// reports whether the cell at local coordinates blocks sight.
+func ComputeVisibility(size int, solid func(x, y, z int) bool) Visibility {
+	// Solid cells start out visited so the fill never enters them.
+	visited := make([]bool, size*size*size)
+	index := func(x, y, z int) int { return (x*size+y)*size + z }
+
+	open := 0
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			for z := 0; z < size; z++ {
+				if solid(x, y, z) {
+					visited[index(x, y, z)] = true
+				} else {
+					open++
+				}
+			}
+		}
+	}
+	if open == len(visited) {
+		return AllVisible
+	}
+
+	var v Visibility
+	var queue [][3]int
+	for start := range visited {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		queue = append(queue[:0], [3]int{start / (size * size), start / size % size, start % size})
+
+		var touched uint8
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			for axis := 0; axis < 3; axis++ {
+				if c[axis] == 0 {
+					touched |= 1 << (axis * 2)
+				}
+				if c[axis] == size-1 {
+					touched |= 1 << (axis*2 + 1)
+				}
+				for _, d := range []int{-1, 1} {
+					n := c
+					n[axis] += d
+					if n[axis] < 0 || n[axis] >= size {
+						continue
+					}
+					i := index(n[0], n[1], n[2])
+					if !visited[i] {
+						visited[i] = true
+						queue = append(queue, n)
+					}
+				}
+			}
+		}
+
+		for a := Face(0); a < faceCount; a++ {
+			for b := Face(0); b < faceCount; b++ {
+				if touched&(1<<a) != 0 && touched&(1<<b) != 0 {
+					v.connect(a, b)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// Key is a section's position in section units.
+type Key [3]int
+
+func (k Key) add(o Key) Key {
+	return Key{k[0] + o[0], k[1] + o[1], k[2] + o[2]}
+}
+
+// Search finds the potentially visible set from the camera's section.
+//
+// Lookup returns a section's visibility; return AllVisible for sections
+// with nothing in them. Accept, when set, rejects sections the search should
+// not enter, such as those outside the view frustum. Sections further than
+// MaxDistance along any axis from the start are never entered.
+type Search struct {
+	Lookup      func(Key) Visibility
+	Accept      func(Key) bool
+	MaxDistance int
+}
+
+type visit struct {
+	key Key
+	// entered is the face the search came in through.
+	entered Face
+	// directions holds every face the search has stepped through so far.
+	// Stepping back the opposite way is never needed to reach a section, so
+	// the search only moves away from the camera.
+	directions uint8
+}
+
+// Visible returns the sections reachable from start, including start.
+func (s Search) Visible(start Key) []Key {
+	seen := map[Key]bool{start: true}
+	result := []Key{start}
+
+	var queue []visit
+	for f := Face(0); f < faceCount; f++ {
+		s.enqueue(&queue, seen, &result, start, start, f, 0)
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		vis := s.Lookup(v.key)
+		for f := Face(0); f < faceCount; f++ {
+			if v.directions&(1<<f.Opposite()) != 0 {
+				continue
+			}
+			if !vis.Connects(v.entered, f) {
+				continue
+			}
+			s.enqueue(&queue, seen, &result, start, v.key, f, v.directions)
+		}
+	}
+	return result
+}
+
+func (s Search) enqueue(queue *[]visit, seen map[Key]bool, result *[]Key, start, from Key, f Face, directions uint8) {
+	next := from.add(f.step())
+	if seen[next] {
+		return
+	}
+	for axis := 0; axis < 3; axis++ {
+		d := next[axis] - start[axis]
+		if d > s.MaxDistance || d < -s.MaxDistance {
+			return
+		}
+	}
+	if s.Accept != nil && !s.Accept(next) {
+		return
+	}
+	seen[next] = true
+	*result = append(*result, next)
+	*queue = append(*queue, visit{key: next, entered: f.Opposite(), directions: directions | 1<<f})
+}
@@ -0,0 +1,147 @@
+package occlusion
+
+import "testing"
+
+const size = 16
+
+func TestComputeVisibilityEmpty(t *testing.T) {
+	v := ComputeVisibility(size, func(x, y, z int) bool { return false })
+	if v != AllVisible {
+		t.Fatalf("empty section = %b, want all visible", v)
+	}
+}
+
+func TestComputeVisibilitySolid(t *testing.T) {
+	v := ComputeVisibility(size, func(x, y, z int) bool { return true })
+	if v != 0 {
+		t.Fatalf("solid section = %b, want none visible", v)
+	}
+}
+
+func TestComputeVisibilityTunnel(t *testing.T) {
+	// A tunnel along X through solid stone.
+	v := ComputeVisibility(size, func(x, y, z int) bool {
+		return !(y == 8 && z == 8)
+	})
+	if !v.Connects(NegX, PosX) || !v.Connects(PosX, NegX) {
+		t.Error("tunnel ends should connect")
+	}
+	for _, f := range []Face{NegY, PosY, NegZ, PosZ} {
+		if v.Connects(NegX, f) {
+			t.Errorf("tunnel should not reach face %d", f)
+		}
+	}
+}
+
+func TestComputeVisibilityWall(t *testing.T) {
+	// A wall at x = 8 splits the section in two.
+	v := ComputeVisibility(size, func(x, y, z int) bool { return x == 8 })
+	if v.Connects(NegX, PosX) {
+		t.Error("wall should separate -X and +X")
+	}
+	if !v.Connects(NegX, PosY) || !v.Connects(PosX, PosY) || !v.Connects(NegY, PosY) {
+		t.Error("both halves should reach the faces along the wall")
+	}
+}
+
+// world is a synthetic grid of sections. Missing sections are empty.
+type world map[Key]Visibility
+
+func (w world) lookup(k Key) Visibility {
+	if v, ok := w[k]; ok {
+		return v
+	}
+	return AllVisible
+}
+
+func contains(keys []Key, k Key) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSearchOpenWorld(t *testing.T) {
+	s := Search{Lookup: world{}.lookup, MaxDistance: 2}
+	visible := s.Visible(Key{})
+	if len(visible) != 5*5*5 {
+		t.Fatalf("open world: %d visible, want %d", len(visible), 5*5*5)
+	}
+}
+
+func TestSearchSealedCave(t *testing.T) {
+	// Solid ground below y = 0 with the camera above it.
+	w := world{}
+	for x := -3; x <= 3; x++ {
+		for y := -3; y < 0; y++ {
+			for z := -3; z <= 3; z++ {
+				w[Key{x, y, z}] = 0
+			}
+		}
+	}
+	// A cave section buried two sections down.
+	cave := Key{0, -2, 0}
+	w[cave] = AllVisible
+
+	s := Search{Lookup: w.lookup, MaxDistance: 3}
+	visible := s.Visible(Key{0, 1, 0})
+	if contains(visible, cave) {
+		t.Error("buried cave should be occluded")
+	}
+	if !contains(visible, Key{0, -1, 0}) {
+		t.Error("the ground's top layer should be visible")
+	}
+	if contains(visible, Key{0, -3, 0}) {
+		t.Error("sections under the top layer should be occluded")
+	}
+
+	// From inside the cave only the cave and its walls are reachable.
+	visible = s.Visible(cave)
+	if len(visible) != 7 {
+		t.Errorf("inside cave: %d visible, want 7", len(visible))
+	}
+}
+
+func TestSearchTunnel(t *testing.T) {
+	// A row of tunnel sections along +X through solid ground.
+	w := world{}
+	for x := -4; x <= 4; x++ {
+		for y := -2; y <= 2; y++ {
+			for z := -2; z <= 2; z++ {
+				w[Key{x, y, z}] = 0
+			}
+		}
+	}
+	var tunnel Visibility
+	tunnel.connect(NegX, PosX)
+	for x := 0; x <= 4; x++ {
+		w[Key{x, 0, 0}] = tunnel
+	}
+
+	s := Search{Lookup: w.lookup, MaxDistance: 4}
+	visible := s.Visible(Key{})
+	if !contains(visible, Key{4, 0, 0}) {
+		t.Error("far end of the tunnel should be visible")
+	}
+	if contains(visible, Key{4, 1, 0}) {
+		t.Error("stone above the far end should be occluded")
+	}
+	if contains(visible, Key{-2, 0, 0}) {
+		t.Error("stone behind the tunnel's start should be occluded past its first section")
+	}
+}
+
+func TestSearchAccept(t *testing.T) {
+	s := Search{
+		Lookup:      world{}.lookup,
+		Accept:      func(k Key) bool { return k[2] <= 0 },
+		MaxDistance: 2,
+	}
+	for _, k := range s.Visible(Key{}) {
+		if k[2] > 0 {
+			t.Fatalf("rejected section %v was visited", k)
+		}
+	}
+}
@@ -36,7 +36,10 @@ func (p *Player) listenForMovement(broker message.MessageBus) {
 	sub := broker.Subscribe()
 	for msg := range sub {
 		if msg.GetTopic() == "PlayerMove" {
-			payload := msg.GetPayload().([3]float32)
+			payload, ok := msg.GetPayload().([3]float32)
+			if !ok {
+				continue
+			}
 			p.ApplyVelocity(component.Velocity{X: payload[0], Y: payload[1], Z: payload[2]})
 		}
 	}
@@ -0,0 +1,155 @@
+package primitive
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AABB is an axis-aligned box.
+type AABB struct {
+	Min, Max mgl32.Vec3
+}
+
+func (b AABB) Translate(offset mgl32.Vec3) AABB {
+	return AABB{Min: b.Min.Add(offset), Max: b.Max.Add(offset)}
+}
+
+// Intersects reports whether the boxes overlap. Touching faces don't count.
+func (b AABB) Intersects(o AABB) bool {
+	return b.Min[0] < o.Max[0] && b.Max[0] > o.Min[0] &&
+		b.Min[1] < o.Max[1] && b.Max[1] > o.Min[1] &&
+		b.Min[2] < o.Max[2] && b.Max[2] > o.Min[2]
+}
+
+// RayIntersect returns the distance along dir at which the ray enters the box
+// and the normal of the face it enters through. Rays starting inside the box
+// hit at t = 0 with a zero normal.
+func (b AABB) RayIntersect(origin, dir mgl32.Vec3) (t float32, normal mgl32.Vec3, ok bool) {
+	tMin := float32(math.Inf(-1))
+	tMax := float32(math.Inf(1))
+	axis := -1
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < b.Min[i] || origin[i] > b.Max[i] {
+				return 0, mgl32.Vec3{}, false
+			}
+			continue
+		}
+		t1 := (b.Min[i] - origin[i]) / dir[i]
+		t2 := (b.Max[i] - origin[i]) / dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin = t1
+			axis = i
+		}
+		if t2 < tMax {
+			tMax = t2
+		}
+		if tMin > tMax {
+			return 0, mgl32.Vec3{}, false
+		}
+	}
+	if tMax < 0 {
+		return 0, mgl32.Vec3{}, false
+	}
+	if tMin < 0 || axis < 0 {
+		return 0, mgl32.Vec3{}, true
+	}
+	if dir[axis] > 0 {
+		normal[axis] = -1
+	} else {
+		normal[axis] = 1
+	}
+	return tMin, normal, true
+}
+
+// RayHit describes where a ray met a cube.
+type RayHit struct {
+	Cube     Cube
+	Distance float32
+	Normal   mgl32.Vec3
+}
+
+// Raycast finds the nearest cube whose model boxes the ray passes through
+// within maxDistance. dir should be normalized.
+func Raycast(cubes []Cube, origin, dir mgl32.Vec3, maxDistance float32) (RayHit, bool) {
+	var hit RayHit
+	found := false
+	for _, cube := range cubes {
+		if cube.Size == 0 || cube.ShouldHide {
+			continue
+		}
+		for _, box := range cube.Boxes() {
+			t, normal, ok := box.RayIntersect(origin, dir)
+			if !ok || t > maxDistance || (found && t >= hit.Distance) {
+				continue
+			}
+			hit = RayHit{Cube: cube, Distance: t, Normal: normal}
+			found = true
+		}
+	}
+	return hit, found
+}
+
+// Collides reports whether box overlaps the collision boxes of any cube.
+func Collides(cubes []Cube, box AABB) bool {
+	for _, cube := range cubes {
+		if cube.Size == 0 {
+			continue
+		}
+		for _, b := range cube.CollisionBoxes() {
+			if b.Intersects(box) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CollidesWith is Collides for a world looked up by cell. It only visits
+// the cells box overlaps.
+func CollidesWith(blocks BlockSource, box AABB) bool {
+	lo := [3]int{}
+	hi := [3]int{}
+	for i := 0; i < 3; i++ {
+		lo[i] = int(math.Floor(float64(box.Min[i])))
+		hi[i] = int(math.Floor(float64(box.Max[i])))
+	}
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				cube, ok := blocks.BlockAt(x, y, z)
+				if !ok || cube.Size == 0 {
+					continue
+				}
+				for _, b := range cube.CollisionBoxes() {
+					if b.Intersects(box) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Slide moves box by move one axis at a time, dropping the part of the
+// move along any axis that would push it into a block, so a box pressed
+// against a wall slides along it. It returns the movement that was kept.
+func Slide(blocks BlockSource, box AABB, move mgl32.Vec3) mgl32.Vec3 {
+	var kept mgl32.Vec3
+	for i := 0; i < 3; i++ {
+		if move[i] == 0 {
+			continue
+		}
+		step := kept
+		step[i] = move[i]
+		if !CollidesWith(blocks, box.Translate(step)) {
+			kept = step
+		}
+	}
+	return kept
+}
@@ -0,0 +1,92 @@
+package primitive
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestRayIntersect(t *testing.T) {
+	box := AABB{Min: mgl32.Vec3{1, 0, 0}, Max: mgl32.Vec3{2, 1, 1}}
+	tests := []struct {
+		name       string
+		origin     mgl32.Vec3
+		dir        mgl32.Vec3
+		wantT      float32
+		wantNormal mgl32.Vec3
+		wantHit    bool
+	}{
+		{"from -x", mgl32.Vec3{-1, 0.5, 0.5}, mgl32.Vec3{1, 0, 0}, 2, mgl32.Vec3{-1, 0, 0}, true},
+		{"from above", mgl32.Vec3{1.5, 3, 0.5}, mgl32.Vec3{0, -1, 0}, 2, mgl32.Vec3{0, 1, 0}, true},
+		{"from +z", mgl32.Vec3{1.5, 0.5, 4}, mgl32.Vec3{0, 0, -1}, 3, mgl32.Vec3{0, 0, 1}, true},
+		{"diagonal", mgl32.Vec3{0, 0.5, -0.5}, mgl32.Vec3{1, 0, 1}, 1, mgl32.Vec3{-1, 0, 0}, true},
+		{"inside", mgl32.Vec3{1.5, 0.5, 0.5}, mgl32.Vec3{0, 1, 0}, 0, mgl32.Vec3{}, true},
+		{"pointing away", mgl32.Vec3{-1, 0.5, 0.5}, mgl32.Vec3{-1, 0, 0}, 0, mgl32.Vec3{}, false},
+		{"parallel outside", mgl32.Vec3{-1, 2, 0.5}, mgl32.Vec3{1, 0, 0}, 0, mgl32.Vec3{}, false},
+		{"passes beside", mgl32.Vec3{-1, 0.5, 0.5}, mgl32.Vec3{1, 1, 0}, 0, mgl32.Vec3{}, false},
+	}
+	for _, tt := range tests {
+		got, normal, ok := box.RayIntersect(tt.origin, tt.dir)
+		if ok != tt.wantHit {
+			t.Errorf("%s: hit = %v, want %v", tt.name, ok, tt.wantHit)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if mgl32.Abs(got-tt.wantT) > 1e-5 {
+			t.Errorf("%s: t = %v, want %v", tt.name, got, tt.wantT)
+		}
+		if normal != tt.wantNormal {
+			t.Errorf("%s: normal = %v, want %v", tt.name, normal, tt.wantNormal)
+		}
+	}
+}
+
+func TestCollides(t *testing.T) {
+	blocks := testBlocks{}
+	blocks.add(0, 0, 0, nil)
+	blocks.add(2, 0, 0, SlabModel)
+	blocks.add(4, 0, 0, CrossModel)
+	var cubes []Cube
+	for _, cube := range blocks {
+		cubes = append(cubes, cube)
+	}
+
+	unit := AABB{Max: mgl32.Vec3{0.5, 0.5, 0.5}}
+	tests := []struct {
+		name string
+		at   mgl32.Vec3
+		want bool
+	}{
+		{"inside a full block", mgl32.Vec3{0.2, 0.2, 0.2}, true},
+		{"touching a face", mgl32.Vec3{1, 0, 0}, false},
+		{"over a slab", mgl32.Vec3{2.2, 0.5, 0.2}, false},
+		{"in a slab", mgl32.Vec3{2.2, 0.4, 0.2}, true},
+		{"in a plant", mgl32.Vec3{4.2, 0.2, 0.2}, false},
+		{"in the air", mgl32.Vec3{0.2, 3, 0.2}, false},
+	}
+	for _, tt := range tests {
+		box := unit.Translate(tt.at)
+		if got := Collides(cubes, box); got != tt.want {
+			t.Errorf("%s: Collides = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := CollidesWith(blocks, box); got != tt.want {
+			t.Errorf("%s: CollidesWith = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSlide(t *testing.T) {
+	blocks := testBlocks{}
+	blocks.add(1, 0, 0, nil)
+	box := AABB{Min: mgl32.Vec3{0.2, 0.2, 0.2}, Max: mgl32.Vec3{0.8, 0.8, 0.8}}
+
+	// Pushing diagonally into the wall keeps the movement along it.
+	if got := Slide(blocks, box, mgl32.Vec3{0.5, 0, 0.5}); got != (mgl32.Vec3{0, 0, 0.5}) {
+		t.Errorf("Slide into a wall = %v, want {0 0 0.5}", got)
+	}
+	if got := Slide(blocks, box, mgl32.Vec3{-0.5, 0.1, 0}); got != (mgl32.Vec3{-0.5, 0.1, 0}) {
+		t.Errorf("Slide in the open = %v, want the whole move", got)
+	}
+}
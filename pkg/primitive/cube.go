@@ -9,6 +9,7 @@ type Cube struct {
 	component.Color
 	Size float32
 	CubeTexture
+	Layers     CubeLayers
 	ShouldHide bool
 	HideFront  bool
 	HideBack   bool
@@ -16,6 +17,14 @@ type Cube struct {
 	HideRight  bool
 	HideTop    bool
 	HideBottom bool
+
+	// Translucent cubes are meshed separately and drawn blended after
+	// opaque geometry. Opacity of 0 means the renderer's default.
+	Translucent bool
+	Opacity     float32
+
+	// Model is the shape of the cube within its cell. nil is a full cube.
+	Model *BlockModel
 }
 
 type CubeTexture struct {
@@ -27,6 +36,16 @@ type CubeTexture struct {
 	Bottom uint32
 }
 
+// CubeLayers holds a layer index into a texture array for each face.
+type CubeLayers struct {
+	Front  uint32
+	Back   uint32
+	Left   uint32
+	Right  uint32
+	Top    uint32
+	Bottom uint32
+}
+
 func (c Cube) Vertices() []float32 {
 	halfSize := c.Size / 2
 	var vertices []float32
@@ -0,0 +1,79 @@
+package primitive
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Plane is the set of points p where Normal·p + D = 0. Points with a
+// positive distance are on the side Normal points to.
+type Plane struct {
+	Normal mgl32.Vec3
+	D      float32
+}
+
+func (p Plane) Distance(point mgl32.Vec3) float32 {
+	return p.Normal.Dot(point) + p.D
+}
+
+// Frustum is six planes facing inward: left, right, bottom, top, near, far.
+type Frustum [6]Plane
+
+// NewFrustum extracts the clipping planes of a view-projection matrix
+// (Gribb and Hartmann). Boxes in world space can then be tested directly.
+func NewFrustum(viewProjection mgl32.Mat4) Frustum {
+	row := func(i int) mgl32.Vec4 {
+		return viewProjection.Row(i)
+	}
+	planes := [6]mgl32.Vec4{
+		row(3).Add(row(0)),
+		row(3).Sub(row(0)),
+		row(3).Add(row(1)),
+		row(3).Sub(row(1)),
+		row(3).Add(row(2)),
+		row(3).Sub(row(2)),
+	}
+
+	var f Frustum
+	for i, p := range planes {
+		normal := p.Vec3()
+		length := normal.Len()
+		f[i] = Plane{Normal: normal.Mul(1 / length), D: p[3] / length}
+	}
+	return f
+}
+
+func (f Frustum) ContainsPoint(point mgl32.Vec3) bool {
+	for _, p := range f {
+		if p.Distance(point) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB reports whether any part of box may be inside the frustum.
+// It is conservative: boxes near a corner of the frustum can pass while
+// lying just outside it.
+func (f Frustum) IntersectsAABB(box AABB) bool {
+	for _, p := range f {
+		// The corner furthest along the plane normal.
+		corner := box.Min
+		for i := 0; i < 3; i++ {
+			if p.Normal[i] >= 0 {
+				corner[i] = box.Max[i]
+			}
+		}
+		if p.Distance(corner) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsSphere reports whether a sphere may be inside the frustum.
+func (f Frustum) IntersectsSphere(center mgl32.Vec3, radius float32) bool {
+	for _, p := range f {
+		if p.Distance(center) < -radius {
+			return false
+		}
+	}
+	return true
+}
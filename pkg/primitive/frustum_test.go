@@ -0,0 +1,79 @@
+package primitive
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func testFrustum() Frustum {
+	projection := mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 100)
+	view := mgl32.LookAtV(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, 1, 0})
+	return NewFrustum(projection.Mul4(view))
+}
+
+func TestFrustumContainsPoint(t *testing.T) {
+	f := testFrustum()
+	tests := []struct {
+		name  string
+		point mgl32.Vec3
+		want  bool
+	}{
+		{"ahead", mgl32.Vec3{0, 0, -10}, true},
+		{"behind", mgl32.Vec3{0, 0, 10}, false},
+		{"closer than near", mgl32.Vec3{0, 0, -0.05}, false},
+		{"beyond far", mgl32.Vec3{0, 0, -101}, false},
+		{"inside left edge", mgl32.Vec3{-9, 0, -10}, true},
+		{"outside left edge", mgl32.Vec3{-11, 0, -10}, false},
+		{"outside top edge", mgl32.Vec3{0, 11, -10}, false},
+	}
+	for _, tt := range tests {
+		if got := f.ContainsPoint(tt.point); got != tt.want {
+			t.Errorf("%s: ContainsPoint(%v) = %v, want %v", tt.name, tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestFrustumPlanesAreNormalized(t *testing.T) {
+	for i, p := range testFrustum() {
+		if l := p.Normal.Len(); l < 0.999 || l > 1.001 {
+			t.Errorf("plane %d normal length = %v", i, l)
+		}
+	}
+}
+
+func TestFrustumIntersectsAABB(t *testing.T) {
+	f := testFrustum()
+	box := func(min, max mgl32.Vec3) AABB { return AABB{Min: min, Max: max} }
+	tests := []struct {
+		name string
+		box  AABB
+		want bool
+	}{
+		{"inside", box(mgl32.Vec3{-1, -1, -11}, mgl32.Vec3{1, 1, -9}), true},
+		{"behind", box(mgl32.Vec3{-1, -1, 5}, mgl32.Vec3{1, 1, 7}), false},
+		{"straddles left plane", box(mgl32.Vec3{-12, -1, -11}, mgl32.Vec3{-9, 1, -9}), true},
+		{"left of frustum", box(mgl32.Vec3{-20, -1, -11}, mgl32.Vec3{-15, 1, -9}), false},
+		{"contains camera", box(mgl32.Vec3{-1, -1, -1}, mgl32.Vec3{1, 1, 1}), true},
+		{"past far plane", box(mgl32.Vec3{-1, -1, -200}, mgl32.Vec3{1, 1, -150}), false},
+		{"surrounds frustum", box(mgl32.Vec3{-500, -500, -500}, mgl32.Vec3{500, 500, 500}), true},
+	}
+	for _, tt := range tests {
+		if got := f.IntersectsAABB(tt.box); got != tt.want {
+			t.Errorf("%s: IntersectsAABB = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFrustumIntersectsSphere(t *testing.T) {
+	f := testFrustum()
+	if !f.IntersectsSphere(mgl32.Vec3{0, 0, -10}, 1) {
+		t.Error("sphere ahead should intersect")
+	}
+	if !f.IntersectsSphere(mgl32.Vec3{-10.5, 0, -10}, 1) {
+		t.Error("sphere touching the left plane should intersect")
+	}
+	if f.IntersectsSphere(mgl32.Vec3{0, 0, 5}, 1) {
+		t.Error("sphere behind should not intersect")
+	}
+}
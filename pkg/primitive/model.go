@@ -0,0 +1,93 @@
+package primitive
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// BlockModel describes the shape of a block inside its unit cell. Boxes are
+// in cell space, from (0,0,0) to (1,1,1). Cross models are drawn as two
+// crossed quads; their boxes are only used for picking.
+type BlockModel struct {
+	Name  string
+	Boxes []AABB
+	Cross bool
+}
+
+// Facing is the horizontal direction a block such as a stair faces.
+type Facing int
+
+const (
+	North Facing = iota // -Z
+	South               // +Z
+	East                // +X
+	West                // -X
+)
+
+var fullBox = AABB{Max: mgl32.Vec3{1, 1, 1}}
+
+var (
+	FullModel = &BlockModel{Name: "full", Boxes: []AABB{fullBox}}
+	SlabModel = &BlockModel{Name: "slab", Boxes: []AABB{
+		{Max: mgl32.Vec3{1, 0.5, 1}},
+	}}
+	TopSlabModel = &BlockModel{Name: "top_slab", Boxes: []AABB{
+		{Min: mgl32.Vec3{0, 0.5, 0}, Max: mgl32.Vec3{1, 1, 1}},
+	}}
+	FenceModel = &BlockModel{Name: "fence", Boxes: []AABB{
+		{Min: mgl32.Vec3{0.375, 0, 0.375}, Max: mgl32.Vec3{0.625, 1, 0.625}},
+	}}
+	CrossModel = &BlockModel{Name: "cross", Cross: true, Boxes: []AABB{
+		{Min: mgl32.Vec3{0.2, 0, 0.2}, Max: mgl32.Vec3{0.8, 0.8, 0.8}},
+	}}
+
+	stairModels = [4]*BlockModel{
+		stairModel("stairs_north", AABB{Min: mgl32.Vec3{0, 0.5, 0}, Max: mgl32.Vec3{1, 1, 0.5}}),
+		stairModel("stairs_south", AABB{Min: mgl32.Vec3{0, 0.5, 0.5}, Max: mgl32.Vec3{1, 1, 1}}),
+		stairModel("stairs_east", AABB{Min: mgl32.Vec3{0.5, 0.5, 0}, Max: mgl32.Vec3{1, 1, 1}}),
+		stairModel("stairs_west", AABB{Min: mgl32.Vec3{0, 0.5, 0}, Max: mgl32.Vec3{0.5, 1, 1}}),
+	}
+)
+
+func stairModel(name string, step AABB) *BlockModel {
+	return &BlockModel{Name: name, Boxes: []AABB{{Max: mgl32.Vec3{1, 0.5, 1}}, step}}
+}
+
+// StairsModel returns a stair whose high step is on the facing side.
+func StairsModel(facing Facing) *BlockModel {
+	return stairModels[facing]
+}
+
+// IsFull reports whether the model fills its whole cell.
+func (m *BlockModel) IsFull() bool {
+	return m == nil || (!m.Cross && len(m.Boxes) == 1 && m.Boxes[0] == fullBox)
+}
+
+// IsFull reports whether the cube fills its cell. Cubes without a model are
+// full.
+func (c Cube) IsFull() bool {
+	return c.Model.IsFull()
+}
+
+func (c Cube) cell() mgl32.Vec3 {
+	return mgl32.Vec3{c.X, c.Y, c.Z}
+}
+
+// Boxes returns the model's boxes in world space, with the cube occupying
+// [x, x+1] on each axis.
+func (c Cube) Boxes() []AABB {
+	if c.Model == nil {
+		return []AABB{fullBox.Translate(c.cell())}
+	}
+	boxes := make([]AABB, len(c.Model.Boxes))
+	for i, b := range c.Model.Boxes {
+		boxes[i] = b.Translate(c.cell())
+	}
+	return boxes
+}
+
+// CollisionBoxes is Boxes without the picking boxes of cross plants, which
+// can be walked through.
+func (c Cube) CollisionBoxes() []AABB {
+	if c.Model != nil && c.Model.Cross {
+		return nil
+	}
+	return c.Boxes()
+}
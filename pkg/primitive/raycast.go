@@ -0,0 +1,94 @@
+package primitive
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// VoxelHit is a cube found by VoxelRaycast. Box is the model box the ray
+// entered and Normal the face of it that was hit.
+type VoxelHit struct {
+	RayHit
+	X, Y, Z int
+	Box     AABB
+}
+
+// BlockSource looks up the cube in the cell at x, y, z, where the cell
+// spans [x, x+1] on each axis.
+type BlockSource interface {
+	BlockAt(x, y, z int) (Cube, bool)
+}
+
+// VoxelRaycast walks the cells along the ray in order, as in Amanatides and
+// Woo's traversal, and returns the first cube whose model boxes the ray
+// passes through within maxDistance. Unlike Raycast it only visits cells on
+// the ray, so its cost doesn't grow with the world. dir needn't be
+// normalized; distances are along the normalized direction.
+func VoxelRaycast(blocks BlockSource, origin, dir mgl32.Vec3, maxDistance float32) (VoxelHit, bool) {
+	if dir.Len() == 0 {
+		return VoxelHit{}, false
+	}
+	dir = dir.Normalize()
+
+	var cell, step [3]int
+	var tMax, tDelta [3]float32
+	for i := 0; i < 3; i++ {
+		cell[i] = int(math.Floor(float64(origin[i])))
+		inf := float32(math.Inf(1))
+		switch {
+		case dir[i] > 0:
+			step[i] = 1
+			tDelta[i] = 1 / dir[i]
+			tMax[i] = (float32(cell[i]+1) - origin[i]) / dir[i]
+		case dir[i] < 0:
+			step[i] = -1
+			tDelta[i] = -1 / dir[i]
+			tMax[i] = (float32(cell[i]) - origin[i]) / dir[i]
+		default:
+			tDelta[i] = inf
+			tMax[i] = inf
+		}
+	}
+
+	t := float32(0)
+	for t <= maxDistance {
+		if hit, ok := hitCell(blocks, cell, origin, dir, maxDistance); ok {
+			return hit, true
+		}
+
+		axis := 0
+		if tMax[1] < tMax[axis] {
+			axis = 1
+		}
+		if tMax[2] < tMax[axis] {
+			axis = 2
+		}
+		t = tMax[axis]
+		cell[axis] += step[axis]
+		tMax[axis] += tDelta[axis]
+	}
+	return VoxelHit{}, false
+}
+
+func hitCell(blocks BlockSource, cell [3]int, origin, dir mgl32.Vec3, maxDistance float32) (VoxelHit, bool) {
+	cube, ok := blocks.BlockAt(cell[0], cell[1], cell[2])
+	if !ok || cube.Size == 0 || cube.ShouldHide {
+		return VoxelHit{}, false
+	}
+	var hit VoxelHit
+	found := false
+	for _, box := range cube.Boxes() {
+		t, normal, ok := box.RayIntersect(origin, dir)
+		if !ok || t > maxDistance || (found && t >= hit.Distance) {
+			continue
+		}
+		hit = VoxelHit{
+			RayHit: RayHit{Cube: cube, Distance: t, Normal: normal},
+			X:      cell[0], Y: cell[1], Z: cell[2],
+			Box: box,
+		}
+		found = true
+	}
+	return hit, found
+}
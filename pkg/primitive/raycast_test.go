@@ -0,0 +1,93 @@
+package primitive
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testBlocks map[[3]int]Cube
+
+func (b testBlocks) BlockAt(x, y, z int) (Cube, bool) {
+	cube, ok := b[[3]int{x, y, z}]
+	return cube, ok
+}
+
+func (b testBlocks) add(x, y, z int, model *BlockModel) {
+	cube := Cube{Size: 1, Model: model}
+	cube.X, cube.Y, cube.Z = float32(x), float32(y), float32(z)
+	b[[3]int{x, y, z}] = cube
+}
+
+func TestVoxelRaycast(t *testing.T) {
+	blocks := testBlocks{}
+	blocks.add(5, 0, 0, nil)
+	blocks.add(0, 0, -3, SlabModel)
+	blocks.add(-4, 2, 2, nil)
+
+	tests := []struct {
+		name       string
+		origin     mgl32.Vec3
+		dir        mgl32.Vec3
+		max        float32
+		want       [3]int
+		wantNormal mgl32.Vec3
+		wantT      float32
+		wantHit    bool
+	}{
+		{"along +x", mgl32.Vec3{0.5, 0.5, 0.5}, mgl32.Vec3{1, 0, 0}, 10, [3]int{5, 0, 0}, mgl32.Vec3{-1, 0, 0}, 4.5, true},
+		{"out of reach", mgl32.Vec3{0.5, 0.5, 0.5}, mgl32.Vec3{1, 0, 0}, 4, [3]int{}, mgl32.Vec3{}, 0, false},
+		{"over a slab", mgl32.Vec3{0.5, 0.75, 0.5}, mgl32.Vec3{0, 0, -1}, 10, [3]int{}, mgl32.Vec3{}, 0, false},
+		{"onto a slab", mgl32.Vec3{0.5, 1.5, -2.5}, mgl32.Vec3{0, -1, 0}, 10, [3]int{0, 0, -3}, mgl32.Vec3{0, 1, 0}, 1, true},
+		{"diagonal", mgl32.Vec3{0.5, 0.5, 0.5}, mgl32.Vec3{-4, 2, 2}, 10, [3]int{-4, 2, 2}, mgl32.Vec3{1, 0, 0}, 0, true},
+		{"miss", mgl32.Vec3{0.5, 0.5, 0.5}, mgl32.Vec3{0, 1, 0}, 50, [3]int{}, mgl32.Vec3{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := VoxelRaycast(blocks, tt.origin, tt.dir, tt.max)
+			if ok != tt.wantHit {
+				t.Fatalf("hit = %v, want %v", ok, tt.wantHit)
+			}
+			if !ok {
+				return
+			}
+			if got := [3]int{hit.X, hit.Y, hit.Z}; got != tt.want {
+				t.Errorf("cell = %v, want %v", got, tt.want)
+			}
+			if hit.Normal != tt.wantNormal {
+				t.Errorf("normal = %v, want %v", hit.Normal, tt.wantNormal)
+			}
+			if tt.wantT != 0 && mgl32.Abs(hit.Distance-tt.wantT) > 1e-4 {
+				t.Errorf("distance = %v, want %v", hit.Distance, tt.wantT)
+			}
+		})
+	}
+}
+
+// VoxelRaycast agrees with the brute force Raycast.
+func TestVoxelRaycastMatchesRaycast(t *testing.T) {
+	blocks := testBlocks{}
+	var cubes []Cube
+	for _, c := range [][3]int{{3, 1, -2}, {2, 2, 2}, {-3, 0, 4}, {0, 4, 1}, {-2, -2, -2}} {
+		blocks.add(c[0], c[1], c[2], StairsModel(East))
+		cubes = append(cubes, blocks[c])
+	}
+	origin := mgl32.Vec3{0.3, 0.6, 0.2}
+	hits := 0
+	for _, dir := range []mgl32.Vec3{
+		{1, 0.2, -0.8}, {1, 1, 1}, {-1, -0.1, 1.2}, {-0.1, 1, 0.3}, {-1, -1, -1}, {0.2, -1, 0.1},
+	} {
+		dir = dir.Normalize()
+		want, wantOK := Raycast(cubes, origin, dir, 20)
+		got, gotOK := VoxelRaycast(blocks, origin, dir, 20)
+		if gotOK != wantOK || (gotOK && (got.Cube != want.Cube || mgl32.Abs(got.Distance-want.Distance) > 1e-4)) {
+			t.Errorf("dir %v: VoxelRaycast = %+v, %v, Raycast = %+v, %v", dir, got.RayHit, gotOK, want, wantOK)
+		}
+		if wantOK {
+			hits++
+		}
+	}
+	if hits == 0 {
+		t.Error("no ray hit anything")
+	}
+}
@@ -0,0 +1,91 @@
+package primitive
+
+import (
+	"math"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+)
+
+// Volume is a scalar field sampled on an integer grid. Values follow the
+// signed distance convention: negative inside, positive outside.
+type Volume struct {
+	Width, Height, Depth int
+	Min                  [3]int
+	density              []float32
+	colors               []component.Color
+}
+
+func NewVolume(width, height, depth int) *Volume {
+	v := &Volume{
+		Width:   width,
+		Height:  height,
+		Depth:   depth,
+		density: make([]float32, width*height*depth),
+		colors:  make([]component.Color, width*height*depth),
+	}
+	for i := range v.density {
+		v.density[i] = 1
+	}
+	return v
+}
+
+// NewVolumeFromCubes samples solid cubes as -1 and air as 1. The volume is
+// padded by one sample on every side so the resulting surface is closed.
+func NewVolumeFromCubes(cubes []Cube) *Volume {
+	if len(cubes) == 0 {
+		return NewVolume(0, 0, 0)
+	}
+
+	min := [3]int{math.MaxInt32, math.MaxInt32, math.MaxInt32}
+	max := [3]int{math.MinInt32, math.MinInt32, math.MinInt32}
+	for _, cube := range cubes {
+		pos := [3]int{int(cube.X), int(cube.Y), int(cube.Z)}
+		for i := 0; i < 3; i++ {
+			if pos[i] < min[i] {
+				min[i] = pos[i]
+			}
+			if pos[i] > max[i] {
+				max[i] = pos[i]
+			}
+		}
+	}
+
+	v := NewVolume(max[0]-min[0]+3, max[1]-min[1]+3, max[2]-min[2]+3)
+	v.Min = [3]int{min[0] - 1, min[1] - 1, min[2] - 1}
+	for _, cube := range cubes {
+		if cube.Size == 0 {
+			continue
+		}
+		v.Set(int(cube.X)-v.Min[0], int(cube.Y)-v.Min[1], int(cube.Z)-v.Min[2], -1, cube.Color)
+	}
+	return v
+}
+
+func (v *Volume) index(x, y, z int) (int, bool) {
+	if x < 0 || y < 0 || z < 0 || x >= v.Width || y >= v.Height || z >= v.Depth {
+		return 0, false
+	}
+	return x + y*v.Width + z*v.Width*v.Height, true
+}
+
+func (v *Volume) Set(x, y, z int, density float32, color component.Color) {
+	if i, ok := v.index(x, y, z); ok {
+		v.density[i] = density
+		v.colors[i] = color
+	}
+}
+
+// Density returns the sample at x, y, z. Samples outside the volume are air.
+func (v *Volume) Density(x, y, z int) float32 {
+	if i, ok := v.index(x, y, z); ok {
+		return v.density[i]
+	}
+	return 1
+}
+
+func (v *Volume) Color(x, y, z int) component.Color {
+	if i, ok := v.index(x, y, z); ok {
+		return v.colors[i]
+	}
+	return component.Color{}
+}
@@ -0,0 +1,270 @@
+// Package raster draws triangles into an image.RGBA on the CPU. It follows
+// OpenGL's conventions: clip space input, counter-clockwise front faces and
+// a depth range of -1 to 1, so the same matrices drive both backends.
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Vertex is a vertex after the vertex stage, with its position in clip
+// space.
+type Vertex struct {
+	Position mgl32.Vec4
+	Varyings
+}
+
+// Varyings are interpolated across a triangle and handed to the fragment
+// function.
+type Varyings struct {
+	Color  mgl32.Vec4
+	UV     mgl32.Vec2
+	Normal mgl32.Vec3
+	// World is the position in world space, for lighting.
+	World mgl32.Vec3
+	// Layer picks a texture and is taken from the first vertex, like a flat
+	// varying.
+	Layer int
+}
+
+// Fragment returns the colour of a pixel from its varyings. Colours with
+// an alpha below 1 are blended over what is already drawn.
+type Fragment func(v Varyings) mgl32.Vec4
+
+// VertexColor is the default fragment function.
+func VertexColor(v Varyings) mgl32.Vec4 {
+	return v.Color
+}
+
+// Rasterizer draws into Image, testing against a depth buffer of the same
+// size.
+type Rasterizer struct {
+	Image *image.RGBA
+	Depth []float32
+
+	// CullBack skips triangles wound clockwise on screen.
+	CullBack bool
+	// DepthWrite is cleared for blended geometry that shouldn't hide what
+	// is drawn after it.
+	DepthWrite bool
+	Fragment   Fragment
+}
+
+func NewRasterizer(width, height int) *Rasterizer {
+	r := &Rasterizer{
+		Image:      image.NewRGBA(image.Rect(0, 0, width, height)),
+		Depth:      make([]float32, width*height),
+		CullBack:   true,
+		DepthWrite: true,
+		Fragment:   VertexColor,
+	}
+	r.ClearDepth()
+	return r
+}
+
+func (r *Rasterizer) Width() int {
+	return r.Image.Rect.Dx()
+}
+
+func (r *Rasterizer) Height() int {
+	return r.Image.Rect.Dy()
+}
+
+// Resize reallocates the buffers when the size changes.
+func (r *Rasterizer) Resize(width, height int) {
+	if width == r.Width() && height == r.Height() {
+		return
+	}
+	r.Image = image.NewRGBA(image.Rect(0, 0, width, height))
+	r.Depth = make([]float32, width*height)
+	r.ClearDepth()
+}
+
+func (r *Rasterizer) Clear(c color.RGBA) {
+	pix := r.Image.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i], pix[i+1], pix[i+2], pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	r.ClearDepth()
+}
+
+func (r *Rasterizer) ClearDepth() {
+	for i := range r.Depth {
+		r.Depth[i] = 1
+	}
+}
+
+// DrawIndexed draws the triangles listed by indices.
+func (r *Rasterizer) DrawIndexed(vertices []Vertex, indices []uint32) {
+	for i := 0; i+2 < len(indices); i += 3 {
+		r.DrawTriangle(vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]])
+	}
+}
+
+// nearEpsilon keeps clipped vertices strictly in front of the camera.
+const nearEpsilon = 1e-5
+
+// DrawTriangle clips a triangle against the near plane and rasterizes what
+// is left. The other planes are handled by the screen bounds and depth
+// range.
+func (r *Rasterizer) DrawTriangle(a, b, c Vertex) {
+	in := [3]Vertex{a, b, c}
+	var out [4]Vertex
+	n := 0
+	for i := 0; i < 3; i++ {
+		cur, next := in[i], in[(i+1)%3]
+		dc, dn := nearDistance(cur), nearDistance(next)
+		if dc >= 0 {
+			out[n] = cur
+			n++
+		}
+		if (dc >= 0) != (dn >= 0) {
+			out[n] = lerpVertex(cur, next, dc/(dc-dn))
+			n++
+		}
+	}
+	for i := 1; i+1 < n; i++ {
+		r.rasterize(out[0], out[i], out[i+1])
+	}
+}
+
+// nearDistance is positive in front of the near plane, z = -w.
+func nearDistance(v Vertex) float32 {
+	return v.Position[2] + v.Position[3] - nearEpsilon
+}
+
+func lerpVertex(a, b Vertex, t float32) Vertex {
+	return Vertex{
+		Position: a.Position.Add(b.Position.Sub(a.Position).Mul(t)),
+		Varyings: Varyings{
+			Color:  a.Color.Add(b.Color.Sub(a.Color).Mul(t)),
+			UV:     a.UV.Add(b.UV.Sub(a.UV).Mul(t)),
+			Normal: a.Normal.Add(b.Normal.Sub(a.Normal).Mul(t)),
+			World:  a.World.Add(b.World.Sub(a.World).Mul(t)),
+			Layer:  a.Layer,
+		},
+	}
+}
+
+// screenVertex is a vertex after the perspective divide.
+type screenVertex struct {
+	x, y, z float32
+	// invW is 1/w, interpolated linearly in screen space for perspective
+	// correct varyings.
+	invW float32
+}
+
+func (r *Rasterizer) toScreen(v Vertex) screenVertex {
+	invW := 1 / v.Position[3]
+	w, h := float32(r.Width()), float32(r.Height())
+	return screenVertex{
+		x:    (v.Position[0]*invW + 1) * 0.5 * w,
+		y:    (1 - v.Position[1]*invW) * 0.5 * h,
+		z:    v.Position[2] * invW,
+		invW: invW,
+	}
+}
+
+func edge(a, b screenVertex, x, y float32) float32 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+func (r *Rasterizer) rasterize(a, b, c Vertex) {
+	sa, sb, sc := r.toScreen(a), r.toScreen(b), r.toScreen(c)
+
+	// Screen y points down, so counter-clockwise triangles have a negative
+	// area here.
+	area := edge(sa, sb, sc.x, sc.y)
+	if area == 0 || (r.CullBack && area > 0) {
+		return
+	}
+
+	width, height := r.Width(), r.Height()
+	minX := clamp(int(math.Floor(float64(min3(sa.x, sb.x, sc.x)))), 0, width-1)
+	maxX := clamp(int(math.Ceil(float64(max3(sa.x, sb.x, sc.x)))), 0, width-1)
+	minY := clamp(int(math.Floor(float64(min3(sa.y, sb.y, sc.y)))), 0, height-1)
+	maxY := clamp(int(math.Ceil(float64(max3(sa.y, sb.y, sc.y)))), 0, height-1)
+
+	va, vb, vc := a.Varyings, b.Varyings, c.Varyings
+	for y := minY; y <= maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float32(x) + 0.5
+			w0 := edge(sb, sc, px, py) / area
+			w1 := edge(sc, sa, px, py) / area
+			w2 := edge(sa, sb, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			z := w0*sa.z + w1*sb.z + w2*sc.z
+			if z < -1 || z > 1 {
+				continue
+			}
+			i := y*width + x
+			if z >= r.Depth[i] {
+				continue
+			}
+
+			// Weights for perspective correct interpolation.
+			p0, p1, p2 := w0*sa.invW, w1*sb.invW, w2*sc.invW
+			sum := p0 + p1 + p2
+			p0, p1, p2 = p0/sum, p1/sum, p2/sum
+			v := Varyings{
+				Color:  va.Color.Mul(p0).Add(vb.Color.Mul(p1)).Add(vc.Color.Mul(p2)),
+				UV:     va.UV.Mul(p0).Add(vb.UV.Mul(p1)).Add(vc.UV.Mul(p2)),
+				Normal: va.Normal.Mul(p0).Add(vb.Normal.Mul(p1)).Add(vc.Normal.Mul(p2)),
+				World:  va.World.Mul(p0).Add(vb.World.Mul(p1)).Add(vc.World.Mul(p2)),
+				Layer:  va.Layer,
+			}
+
+			r.blend(i, r.Fragment(v))
+			if r.DepthWrite {
+				r.Depth[i] = z
+			}
+		}
+	}
+}
+
+func (r *Rasterizer) blend(i int, c mgl32.Vec4) {
+	pix := r.Image.Pix[i*4 : i*4+4]
+	alpha := clampf(c[3])
+	for ch := 0; ch < 3; ch++ {
+		dst := float32(pix[ch]) / 255
+		pix[ch] = uint8(clampf(c[ch]*alpha+dst*(1-alpha))*255 + 0.5)
+	}
+	dstAlpha := float32(pix[3]) / 255
+	pix[3] = uint8(clampf(alpha+dstAlpha*(1-alpha))*255 + 0.5)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func clampf(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
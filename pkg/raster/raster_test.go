@@ -0,0 +1,127 @@
+package raster
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var black = color.RGBA{0, 0, 0, 255}
+
+func vertex(x, y, z float32, c mgl32.Vec4) Vertex {
+	return Vertex{Position: mgl32.Vec4{x, y, z, 1}, Varyings: Varyings{Color: c}}
+}
+
+func TestDrawTriangleFillsCoveredPixels(t *testing.T) {
+	r := NewRasterizer(8, 8)
+	r.Clear(black)
+	red := mgl32.Vec4{1, 0, 0, 1}
+	r.DrawTriangle(vertex(-1, -1, 0, red), vertex(1, -1, 0, red), vertex(-1, 1, 0, red))
+
+	if got := r.Image.RGBAAt(1, 6); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("pixel inside triangle = %v", got)
+	}
+	if got := r.Image.RGBAAt(6, 1); got != black {
+		t.Errorf("pixel outside triangle = %v", got)
+	}
+}
+
+func TestDrawTriangleCullsBackFaces(t *testing.T) {
+	r := NewRasterizer(8, 8)
+	r.Clear(black)
+	red := mgl32.Vec4{1, 0, 0, 1}
+	// Clockwise on screen.
+	r.DrawTriangle(vertex(-1, -1, 0, red), vertex(-1, 1, 0, red), vertex(1, -1, 0, red))
+	if got := r.Image.RGBAAt(1, 6); got != black {
+		t.Errorf("back face was drawn: %v", got)
+	}
+
+	r.CullBack = false
+	r.DrawTriangle(vertex(-1, -1, 0, red), vertex(-1, 1, 0, red), vertex(1, -1, 0, red))
+	if got := r.Image.RGBAAt(1, 6); got == black {
+		t.Error("back face should draw with culling off")
+	}
+}
+
+func TestDepthTest(t *testing.T) {
+	r := NewRasterizer(4, 4)
+	r.Clear(black)
+	near := mgl32.Vec4{0, 1, 0, 1}
+	far := mgl32.Vec4{0, 0, 1, 1}
+	quad := func(z float32, c mgl32.Vec4) {
+		r.DrawTriangle(vertex(-1, -1, z, c), vertex(1, -1, z, c), vertex(1, 1, z, c))
+		r.DrawTriangle(vertex(-1, -1, z, c), vertex(1, 1, z, c), vertex(-1, 1, z, c))
+	}
+	quad(-0.5, near)
+	quad(0.5, far)
+	if got := r.Image.RGBAAt(2, 2); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("far quad drew over near quad: %v", got)
+	}
+}
+
+func TestBlending(t *testing.T) {
+	r := NewRasterizer(4, 4)
+	r.Clear(black)
+	half := mgl32.Vec4{1, 1, 1, 0.5}
+	r.DrawTriangle(vertex(-1, -1, 0, half), vertex(1, -1, 0, half), vertex(-1, 1, 0, half))
+	got := r.Image.RGBAAt(0, 3)
+	if got.R < 126 || got.R > 129 {
+		t.Errorf("half white over black = %v, want grey", got)
+	}
+}
+
+func TestPerspectiveCorrectInterpolation(t *testing.T) {
+	projection := mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 100)
+
+	// A floor at y = -1 running from z = -1 to z = -20, with U tracking
+	// depth along it.
+	project := func(x, z, u float32) Vertex {
+		return Vertex{
+			Position: projection.Mul4x1(mgl32.Vec4{x, -1, z, 1}),
+			Varyings: Varyings{Color: mgl32.Vec4{1, 1, 1, 1}, UV: mgl32.Vec2{u, 0}},
+		}
+	}
+	vertices := []Vertex{project(-10, -1, 0), project(10, -1, 0), project(10, -20, 1), project(-10, -20, 1)}
+
+	r := NewRasterizer(64, 64)
+	r.CullBack = false
+	r.Fragment = func(v Varyings) mgl32.Vec4 {
+		return mgl32.Vec4{v.UV[0], 0, 0, 1}
+	}
+	r.DrawIndexed(vertices, []uint32{0, 1, 2, 0, 2, 3})
+
+	// Row 48's centre is at NDC y = -0.515625, which sees the floor at
+	// z = -1/0.515625. Interpolating U linearly on screen would give about
+	// 0.5 here instead.
+	want := float32(1/0.515625-1) / 19
+	got := float32(r.Image.RGBAAt(32, 48).R) / 255
+	if got < want-0.01 || got > want+0.01 {
+		t.Errorf("U at row 48 = %v, want %v", got, want)
+	}
+}
+
+func TestNearPlaneClipping(t *testing.T) {
+	projection := mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 100)
+	r := NewRasterizer(16, 16)
+	r.Clear(black)
+	r.CullBack = false
+	white := mgl32.Vec4{1, 1, 1, 1}
+	v := func(x, y, z float32) Vertex {
+		return Vertex{Position: projection.Mul4x1(mgl32.Vec4{x, y, z, 1}), Varyings: Varyings{Color: white}}
+	}
+	// One vertex is behind the camera.
+	r.DrawTriangle(v(-1, -1, -2), v(1, -1, -2), v(0, 1, 5))
+	drawn := 0
+	for _, d := range r.Depth {
+		if d < 1 {
+			drawn++
+			if d < -1 {
+				t.Fatalf("depth %v outside range", d)
+			}
+		}
+	}
+	if drawn == 0 {
+		t.Error("clipped triangle drew nothing")
+	}
+}
@@ -0,0 +1,41 @@
+package raster
+
+import (
+	"image"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Texture is sampled with nearest filtering and repeat wrapping, matching
+// the GL textures the block renderers create.
+type Texture struct {
+	image image.Image
+}
+
+func NewTexture(img image.Image) *Texture {
+	return &Texture{image: img}
+}
+
+// Sample returns the texel at uv as a colour in [0, 1]. Images are uploaded
+// to GL unflipped, so v = 0 is the top row.
+func (t *Texture) Sample(uv mgl32.Vec2) mgl32.Vec4 {
+	bounds := t.image.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	x := wrap(int(math.Floor(float64(uv[0]*float32(w)))), w)
+	y := wrap(int(math.Floor(float64(uv[1]*float32(h)))), h)
+	r, g, b, a := t.image.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+	if a == 0 {
+		return mgl32.Vec4{}
+	}
+	// RGBA returns premultiplied values.
+	return mgl32.Vec4{float32(r) / float32(a), float32(g) / float32(a), float32(b) / float32(a), float32(a) / 0xffff}
+}
+
+func wrap(v, n int) int {
+	v %= n
+	if v < 0 {
+		v += n
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package scene
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+// Load reads a scene with one cube per line as "x y z" or "x y z r g b",
+// with colour channels from 0 to 1. Blank lines and lines starting with #
+// are skipped. Cubes without a colour are grey.
+func Load(r io.Reader) ([]primitive.Cube, error) {
+	var cubes []primitive.Cube
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 3 && len(fields) != 6 {
+			return nil, fmt.Errorf("line %d: want 3 or 6 fields, got %d", line, len(fields))
+		}
+
+		var pos [3]int
+		for i := range pos {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			pos[i] = v
+		}
+		color := component.Color{0.8, 0.8, 0.8}
+		if len(fields) == 6 {
+			for i := range color {
+				v, err := strconv.ParseFloat(fields[3+i], 32)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				color[i] = float32(v)
+			}
+		}
+		cubes = append(cubes, cube(pos[0], pos[1], pos[2], color))
+	}
+	return cubes, scanner.Err()
+}
+
+// LoadFile reads a scene file written in the format Load expects.
+func LoadFile(path string) ([]primitive.Cube, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cubes, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cubes, nil
+}
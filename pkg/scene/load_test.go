@@ -0,0 +1,37 @@
+package scene
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+)
+
+func TestLoad(t *testing.T) {
+	cubes, err := Load(strings.NewReader(`# a post
+0 0 0
+
+0 1 0 1 0 0.5
+-2 3 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cubes) != 3 {
+		t.Fatalf("got %d cubes, want 3", len(cubes))
+	}
+	if c := cubes[1]; c.Y != 1 || c.Color != (component.Color{1, 0, 0.5}) || c.Size != 1 {
+		t.Errorf("second cube = %+v", c)
+	}
+	if c := cubes[2]; c.X != -2 || c.Z != 4 {
+		t.Errorf("third cube at %v, %v, %v", c.X, c.Y, c.Z)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, input := range []string{"1 2", "1 2 x", "1 2 3 0 0"} {
+		if _, err := Load(strings.NewReader(input)); err == nil {
+			t.Errorf("Load(%q) should fail", input)
+		}
+	}
+}
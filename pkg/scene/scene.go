@@ -0,0 +1,186 @@
+package scene
+
+import (
+	"math/rand"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+func cube(x, y, z int, color component.Color) primitive.Cube {
+	return primitive.Cube{
+		Position: component.Position{
+			X: float32(x),
+			Y: float32(y),
+			Z: float32(z),
+		},
+		Size:  1.0,
+		Color: color,
+	}
+}
+
+// Solid fills a size³ volume.
+func Solid(size int) []primitive.Cube {
+	var cubes []primitive.Cube
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			for z := 0; z < size; z++ {
+				cubes = append(cubes, cube(x, y, z, component.Color{
+					float32(x) / float32(size),
+					float32(y) / float32(size),
+					float32(z) / float32(size),
+				}))
+			}
+		}
+	}
+	return cubes
+}
+
+// Noise fills each cell of a size³ volume with probability fill.
+func Noise(size int, fill float64, seed int64) []primitive.Cube {
+	rng := rand.New(rand.NewSource(seed))
+	var cubes []primitive.Cube
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			for z := 0; z < size; z++ {
+				if rng.Float64() >= fill {
+					continue
+				}
+				cubes = append(cubes, cube(x, y, z, component.Color{rng.Float32(), rng.Float32(), rng.Float32()}))
+			}
+		}
+	}
+	return cubes
+}
+
+// Terrain builds random height columns coloured by height, like test/greedy_chunk.
+func Terrain(size int, seed int64) []primitive.Cube {
+	rng := rand.New(rand.NewSource(seed))
+	var cubes []primitive.Cube
+	for x := 0; x < size; x++ {
+		for z := 0; z < size; z++ {
+			height := rng.Intn(size/2) + size/4
+			for y := 0; y < height; y++ {
+				cubes = append(cubes, cube(x, y, z, terrainColor(y, size)))
+			}
+		}
+	}
+	return cubes
+}
+
+// Pond is Terrain with water filling the low columns up to the midline and
+// a glass pillar in the middle.
+func Pond(size int, seed int64) []primitive.Cube {
+	rng := rand.New(rand.NewSource(seed))
+	water := size / 2
+	var cubes []primitive.Cube
+	for x := 0; x < size; x++ {
+		for z := 0; z < size; z++ {
+			height := rng.Intn(size/2) + size/4
+			for y := 0; y < height; y++ {
+				cubes = append(cubes, cube(x, y, z, terrainColor(y, size)))
+			}
+			for y := height; y < water; y++ {
+				cubes = append(cubes, translucent(x, y, z, component.Color{0.2, 0.4, 0.9}, 0.6))
+			}
+		}
+	}
+
+	for y := water; y < water+size/4; y++ {
+		cubes = append(cubes, translucent(size/2, y, size/2, component.Color{0.8, 0.9, 1.0}, 0.3))
+	}
+	return cubes
+}
+
+func translucent(x, y, z int, color component.Color, opacity float32) primitive.Cube {
+	c := cube(x, y, z, color)
+	c.Translucent = true
+	c.Opacity = opacity
+	return c
+}
+
+// Shapes lays out a row of each block model on a stone floor.
+func Shapes() []primitive.Cube {
+	var cubes []primitive.Cube
+	for x := 0; x < 12; x++ {
+		for z := 0; z < 8; z++ {
+			cubes = append(cubes, cube(x, 0, z, component.Color{0.5, 0.5, 0.5}))
+		}
+	}
+
+	wood := component.Color{0.6, 0.4, 0.2}
+	rows := []*primitive.BlockModel{
+		primitive.SlabModel,
+		primitive.TopSlabModel,
+		primitive.StairsModel(primitive.East),
+		primitive.FenceModel,
+	}
+	for z, model := range rows {
+		for x := 1; x < 11; x++ {
+			c := cube(x, 1, z*2, wood)
+			c.Model = model
+			cubes = append(cubes, c)
+		}
+	}
+
+	for x := 1; x < 11; x += 2 {
+		c := cube(x, 1, 7, component.Color{0.2, 0.8, 0.2})
+		c.Model = primitive.CrossModel
+		cubes = append(cubes, c)
+	}
+	return cubes
+}
+
+func terrainColor(y, size int) component.Color {
+	if y < size/4 {
+		return component.Color{0.6, 0.4, 0.2}
+	} else if y < size/2 {
+		return component.Color{0.2, 1.0, 0.2}
+	}
+	return component.Color{0.8, 0.8, 0.8}
+}
+
+// Cat generates a simple cat shape using blocks
+func Cat() []primitive.Cube {
+	var cubes []primitive.Cube
+	color := component.Color{0.8, 0.8, 0.8} // Grey
+
+	// Body
+	for x := 2; x < 6; x++ {
+		for z := 2; z < 6; z++ {
+			for y := 0; y < 4; y++ {
+				cubes = append(cubes, cube(x, y, z, color))
+			}
+		}
+	}
+
+	// Head
+	for x := 3; x < 5; x++ {
+		for z := 1; z < 3; z++ {
+			for y := 4; y < 6; y++ {
+				cubes = append(cubes, cube(x, y, z, color))
+			}
+		}
+	}
+
+	// Ears
+	for x := 3; x < 5; x++ {
+		cubes = append(cubes, cube(x, 6, 1, color))
+	}
+
+	// Legs
+	for x := 2; x < 6; x += 3 {
+		for z := 2; z < 6; z += 3 {
+			for y := 0; y < 2; y++ {
+				cubes = append(cubes, cube(x, y, z, color))
+			}
+		}
+	}
+
+	// Tail
+	for x := 6; x < 8; x++ {
+		cubes = append(cubes, cube(x, 3, 4, color))
+	}
+
+	return cubes
+}
@@ -0,0 +1,114 @@
+// Package text lays out strings in a fixed width bitmap font for drawing as
+// textured quads.
+package text
+
+import (
+	"image"
+	"image/draw"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+// Font is a fixed width font whose glyphs are stacked top to bottom in an
+// atlas, each Width by Height pixels.
+type Font struct {
+	Atlas   *image.Alpha
+	Width   int
+	Height  int
+	Advance int
+	ranges  []basicfont.Range
+}
+
+// Default is the 7x13 font from golang.org/x/image, covering printable
+// ASCII.
+func Default() *Font {
+	return FromFace(basicfont.Face7x13)
+}
+
+// FromFace copies a basicfont face's glyph masks into a Font.
+func FromFace(face *basicfont.Face) *Font {
+	bounds := face.Mask.Bounds()
+	atlas := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(atlas, atlas.Rect, face.Mask, bounds.Min, draw.Src)
+	return &Font{
+		Atlas:   atlas,
+		Width:   face.Width,
+		Height:  face.Height,
+		Advance: face.Advance,
+		ranges:  face.Ranges,
+	}
+}
+
+// Glyph returns where r is in the atlas. Runes the font lacks get the
+// replacement glyph, or the first glyph when there is none.
+func (f *Font) Glyph(r rune) image.Rectangle {
+	index, ok := f.index(r)
+	if !ok {
+		index, _ = f.index('�')
+	}
+	return image.Rect(0, index*f.Height, f.Width, (index+1)*f.Height)
+}
+
+func (f *Font) index(r rune) (int, bool) {
+	for _, rr := range f.ranges {
+		if rr.Low <= r && r < rr.High {
+			return int(r-rr.Low) + rr.Offset, true
+		}
+	}
+	return 0, false
+}
+
+// Quad is one glyph on screen, in pixels from the top left, with its
+// texture coordinates in the atlas.
+type Quad struct {
+	X0, Y0, X1, Y1 float32
+	U0, V0, U1, V1 float32
+}
+
+// Layout places s with its top left corner at x, y, each glyph pixel
+// scale screen pixels across. Newlines start a new line and spaces take
+// up room without a quad.
+func (f *Font) Layout(s string, x, y, scale float32) []Quad {
+	atlasW := float32(f.Atlas.Rect.Dx())
+	atlasH := float32(f.Atlas.Rect.Dy())
+	w, h := float32(f.Width)*scale, float32(f.Height)*scale
+
+	quads := make([]Quad, 0, len(s))
+	penX, penY := x, y
+	for _, r := range s {
+		switch r {
+		case '\n':
+			penX = x
+			penY += h
+			continue
+		case ' ':
+			penX += float32(f.Advance) * scale
+			continue
+		}
+		g := f.Glyph(r)
+		quads = append(quads, Quad{
+			X0: penX, Y0: penY, X1: penX + w, Y1: penY + h,
+			U0: float32(g.Min.X) / atlasW, V0: float32(g.Min.Y) / atlasH,
+			U1: float32(g.Max.X) / atlasW, V1: float32(g.Max.Y) / atlasH,
+		})
+		penX += float32(f.Advance) * scale
+	}
+	return quads
+}
+
+// Measure returns the width and height s takes up at scale.
+func (f *Font) Measure(s string, scale float32) (float32, float32) {
+	lines, longest, current := 1, 0, 0
+	for _, r := range s {
+		if r == '\n' {
+			lines++
+			current = 0
+			continue
+		}
+		current++
+		if current > longest {
+			longest = current
+		}
+	}
+	return float32(longest*f.Advance) * scale, float32(lines*f.Height) * scale
+}
@@ -0,0 +1,65 @@
+package text
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGlyphs(t *testing.T) {
+	f := Default()
+	if got, want := f.Glyph('!'), image.Rect(0, 13, 6, 26); got != want {
+		t.Errorf("Glyph('!') = %v, want %v", got, want)
+	}
+	if f.Glyph('é') != f.Glyph('�') {
+		t.Error("a missing rune should use the replacement glyph")
+	}
+
+	// Every printable glyph but space has ink.
+	for r := '!'; r <= '~'; r++ {
+		g := f.Glyph(r)
+		ink := false
+		for y := g.Min.Y; y < g.Max.Y && !ink; y++ {
+			for x := g.Min.X; x < g.Max.X; x++ {
+				if f.Atlas.AlphaAt(x, y).A > 0 {
+					ink = true
+					break
+				}
+			}
+		}
+		if !ink {
+			t.Errorf("glyph %q is blank", r)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	f := Default()
+	quads := f.Layout("ab c\nd", 10, 20, 2)
+	if len(quads) != 4 {
+		t.Fatalf("got %d quads, want 4 (no quad for the space)", len(quads))
+	}
+
+	a, b, c, d := quads[0], quads[1], quads[2], quads[3]
+	if a.X0 != 10 || a.Y0 != 20 || a.X1 != 10+6*2 || a.Y1 != 20+13*2 {
+		t.Errorf("first quad = %+v", a)
+	}
+	if b.X0 != 10+7*2 {
+		t.Errorf("second glyph at x %v, want one advance on", b.X0)
+	}
+	if c.X0 != 10+3*7*2 {
+		t.Errorf("glyph after the space at x %v, want three advances on", c.X0)
+	}
+	if d.X0 != 10 || d.Y0 != 20+13*2 {
+		t.Errorf("glyph after the newline at %v, %v, want the start of the next line", d.X0, d.Y0)
+	}
+	if a.U0 != 0 || a.U1 != 1 || a.V1 <= a.V0 {
+		t.Errorf("texture coordinates = %+v", a)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	w, h := Default().Measure("abc\nde", 1)
+	if w != 21 || h != 26 {
+		t.Errorf("Measure = %v, %v, want 21, 26", w, h)
+	}
+}
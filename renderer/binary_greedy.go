@@ -0,0 +1,229 @@
+package renderer
+
+import (
+	"fmt"
+	"math/bits"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// BinaryGreedyMesher produces the same quads as GreedyMesher, but stores each
+// column of the volume as a uint64 bitmask. Face detection becomes a shift and
+// xor per column and runs are merged with trailing zero counts.
+type BinaryGreedyMesher struct {
+	meshBuffers
+	vertices []float32
+	indices  []uint32
+	size     int
+
+	// columns[d][x[u]*size+x[v]] has bit p set when the cell at x[d] = p is solid.
+	columns [3][]uint64
+	// planes[p*size+x[v]] has bit x[u] set when the plane at x[d] = p has a face.
+	planes []uint64
+	colors []component.Color
+}
+
+const maxBinaryGreedySize = 63
+
+func NewBinaryGreedyMesher() *BinaryGreedyMesher {
+	return &BinaryGreedyMesher{size: greedyMesherSize}
+}
+
+func (m *BinaryGreedyMesher) CreateMesh(cubes []primitive.Cube) {
+	m.GenerateMesh(cubes)
+	m.setupBuffers()
+}
+
+func (m *BinaryGreedyMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.generateMesh(cubes)
+}
+
+func (m *BinaryGreedyMesher) VertexLayout() VertexLayout {
+	return PositionColorNormalLayout
+}
+
+func (m *BinaryGreedyMesher) Bind() {
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *BinaryGreedyMesher) Unbind() {
+	gl.BindVertexArray(0)
+}
+
+func (m *BinaryGreedyMesher) Draw() {
+	m.EnableBackfaceCulling()
+	gl.BindVertexArray(m.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(0)
+}
+
+func (m *BinaryGreedyMesher) GetMesh() ([]float32, []uint32) {
+	return m.vertices, m.indices
+}
+
+func (m *BinaryGreedyMesher) String() string {
+	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
+}
+
+func (m *BinaryGreedyMesher) Clone() Mesher {
+	return &BinaryGreedyMesher{size: m.size}
+}
+
+func (m *BinaryGreedyMesher) CacheKey() string {
+	return fmt.Sprintf("binary-greedy/1/size=%d", m.size)
+}
+
+func (m *BinaryGreedyMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.vertices = append(m.vertices[:0], vertices...)
+	m.indices = append(m.indices[:0], indices...)
+	m.setupBuffers()
+}
+
+func (m *BinaryGreedyMesher) generateMesh(cubes []primitive.Cube) {
+	if m.size > maxBinaryGreedySize {
+		m.size = maxBinaryGreedySize
+	}
+	m.vertices = m.vertices[:0]
+	m.indices = m.indices[:0]
+
+	m.populateColumns(cubes)
+	for d := 0; d < 3; d++ {
+		m.generateDirectionMesh(d)
+	}
+}
+
+func (m *BinaryGreedyMesher) populateColumns(cubes []primitive.Cube) {
+	n := m.size
+	for d := range m.columns {
+		m.columns[d] = resizeMasks(m.columns[d], n*n)
+	}
+	m.planes = resizeMasks(m.planes, (n+1)*n)
+	if len(m.colors) != n*n*n {
+		m.colors = make([]component.Color, n*n*n)
+	}
+
+	for _, cube := range cubes {
+		x, y, z := int(cube.X), int(cube.Y), int(cube.Z)
+		if x < 0 || x >= n || y < 0 || y >= n || z < 0 || z >= n {
+			continue
+		}
+		m.columns[0][y*n+z] |= 1 << x
+		m.columns[1][z*n+x] |= 1 << y
+		m.columns[2][x*n+y] |= 1 << z
+		m.colors[x+y*n+z*n*n] = cube.Color
+	}
+}
+
+func (m *BinaryGreedyMesher) generateDirectionMesh(d int) {
+	n := m.size
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	columns := m.columns[d]
+	planes := m.planes
+	for i := range planes {
+		planes[i] = 0
+	}
+
+	// A face sits on plane p wherever solid(p-1) != solid(p).
+	for cu := 0; cu < n; cu++ {
+		for cv := 0; cv < n; cv++ {
+			column := columns[cu*n+cv]
+			faces := column ^ (column << 1)
+			for faces != 0 {
+				p := bits.TrailingZeros64(faces)
+				planes[p*n+cv] |= 1 << cu
+				faces &= faces - 1
+			}
+		}
+	}
+
+	for p := 0; p <= n; p++ {
+		for j := 0; j < n; j++ {
+			row := planes[p*n+j]
+			for row != 0 {
+				i := bits.TrailingZeros64(row)
+				w := bits.TrailingZeros64(^(row >> i))
+				run := (uint64(1)<<w - 1) << i
+
+				h := 1
+				for j+h < n && planes[p*n+j+h]&run == run {
+					planes[p*n+j+h] &^= run
+					h++
+				}
+				row &^= run
+
+				x := [3]int{}
+				x[d], x[u], x[v] = p, i, j
+				du := [3]int{}
+				dv := [3]int{}
+				du[u] = w
+				dv[v] = h
+
+				positive := p < n && columns[i*n+j]&(1<<p) != 0
+				m.addQuad(d, x, du, dv, positive, m.faceColor(d, x, positive))
+			}
+		}
+	}
+}
+
+func (m *BinaryGreedyMesher) faceColor(d int, x [3]int, positive bool) component.Color {
+	if !positive {
+		x[d]--
+	}
+	n := m.size
+	return m.colors[x[0]+x[1]*n+x[2]*n*n]
+}
+
+func (m *BinaryGreedyMesher) addQuad(d int, x, du, dv [3]int, positive bool, color component.Color) {
+	n := [3]float32{}
+	n[d] = -1
+	if !positive {
+		du, dv = dv, du
+		n[d] = 1
+	}
+
+	idx := uint32(len(m.vertices) / 9)
+	m.vertices = append(m.vertices,
+		float32(x[0]), float32(x[1]), float32(x[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+du[0]), float32(x[1]+du[1]), float32(x[2]+du[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+dv[0]), float32(x[1]+dv[1]), float32(x[2]+dv[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+du[0]+dv[0]), float32(x[1]+du[1]+dv[1]), float32(x[2]+du[2]+dv[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+	)
+
+	// Same winding as GreedyMesher's add*FaceIndices.
+	if positive != (d == 1) {
+		m.indices = append(m.indices,
+			idx+2, idx+1, idx,
+			idx+2, idx+3, idx+1,
+		)
+	} else {
+		m.indices = append(m.indices,
+			idx, idx+2, idx+1,
+			idx+1, idx+2, idx+3,
+		)
+	}
+}
+
+func resizeMasks(masks []uint64, n int) []uint64 {
+	if cap(masks) < n {
+		return make([]uint64, n)
+	}
+	masks = masks[:n]
+	for i := range masks {
+		masks[i] = 0
+	}
+	return masks
+}
+
+func (m *BinaryGreedyMesher) setupBuffers() {
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.STATIC_DRAW)
+}
+
+func (m *BinaryGreedyMesher) EnableBackfaceCulling() {
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.FrontFace(gl.CCW)
+}
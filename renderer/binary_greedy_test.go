@@ -0,0 +1,85 @@
+package renderer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+)
+
+// quadGeometry drops colours, which GreedyMesher picks differently.
+func quadGeometry(vertices []float32) [][6]float32 {
+	var geometry [][6]float32
+	for i := 0; i < len(vertices); i += 9 {
+		geometry = append(geometry, [6]float32{
+			vertices[i], vertices[i+1], vertices[i+2],
+			vertices[i+6], vertices[i+7], vertices[i+8],
+		})
+	}
+	return geometry
+}
+
+func TestBinaryGreedyMatchesGreedy(t *testing.T) {
+	scenes := map[string][]primitive.Cube{
+		"terrain16": scene.Terrain(16, 1),
+		"terrain32": scene.Terrain(32, 3),
+		"noise":     scene.Noise(16, 0.5, 2),
+		"cat":       scene.Cat(),
+	}
+	for name, cubes := range scenes {
+		for _, size := range []int{15, 16, 32} {
+			t.Run(fmt.Sprintf("%s/%d", name, size), func(t *testing.T) {
+				greedy := &GreedyMesher{size: size}
+				greedy.GenerateMesh(cubes)
+				binary := &BinaryGreedyMesher{size: size}
+				binary.GenerateMesh(cubes)
+
+				want := quadGeometry(greedy.vertices)
+				got := quadGeometry(binary.vertices)
+				if len(got) != len(want) {
+					t.Fatalf("vertex count = %d, want %d", len(got), len(want))
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Fatalf("vertex %d = %v, want %v", i, got[i], want[i])
+					}
+				}
+				if len(binary.indices) != len(greedy.indices) {
+					t.Fatalf("index count = %d, want %d", len(binary.indices), len(greedy.indices))
+				}
+				for i := range greedy.indices {
+					if binary.indices[i] != greedy.indices[i] {
+						t.Fatalf("index %d = %d, want %d", i, binary.indices[i], greedy.indices[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGreedyMesher(b *testing.B) {
+	for _, size := range []int{16, 32} {
+		cubes := scene.Terrain(size, 1)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			m := &GreedyMesher{size: size}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.GenerateMesh(cubes)
+			}
+		})
+	}
+}
+
+func BenchmarkBinaryGreedyMesher(b *testing.B) {
+	for _, size := range []int{16, 32} {
+		cubes := scene.Terrain(size, 1)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			m := &BinaryGreedyMesher{size: size}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.GenerateMesh(cubes)
+			}
+		})
+	}
+}
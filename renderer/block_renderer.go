@@ -1,8 +1,6 @@
 package renderer
 
 import (
-	"fmt"
-
 	"github.com/dfirebaugh/cube/pkg/message"
 	"github.com/dfirebaugh/cube/pkg/primitive"
 	"github.com/dfirebaugh/cube/shader"
@@ -17,8 +15,12 @@ type BlockRenderer struct {
 	messageBus  message.MessageBus
 	cubeProgram uint32
 	cubes       []primitive.Cube
+	batch       textureBatch
+	dirty       bool
 	wireframe   bool
 	events      chan string
+	shadows     shadowPass
+	sceneState
 }
 
 func (r *BlockRenderer) SetCamera(camera Camera) {
@@ -51,90 +53,63 @@ func (r *BlockRenderer) Render() {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 	}
 
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	if r.dirty {
+		r.batch.build(r.cubes)
+		r.dirty = false
+	}
 
-	view := r.camera.GetViewMatrix()
+	model := mgl32.Ident4()
+	if r.shadows.enabled() {
+		r.shadows.renderBatch(r.camera, r.window, r.Lighting().SunDirection, &r.batch, model)
+	}
 
-	windowWidth, windowHeight := r.window.GetSize()
-	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/float32(windowHeight), 0.1, 100.0)
+	gl.UseProgram(r.cubeProgram)
+
+	view := r.camera.GetViewMatrix()
+	projection := perspective(r.camera, r.window)
 
 	viewLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("view\x00"))
 	projLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("projection\x00"))
 
 	gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
 	gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
+	r.setSceneUniforms(r.cubeProgram, &r.shadows, r.camera.GetPosition())
 
-	for _, cube := range r.cubes {
-		model := mgl32.Translate3D(cube.Position.X, cube.Position.Y, cube.Position.Z)
-		modelLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("model\x00"))
-		gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
+	modelLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("model\x00"))
+	gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
 
-		r.renderCube(cube)
-	}
+	r.batch.draw()
 	r.drainEvents()
 }
 
-func (r *BlockRenderer) renderCube(cube primitive.Cube) {
-	vertices := cube.Vertices()
-	indices := cube.Indices(0)
-
-	if len(vertices) == 0 || len(indices) == 0 {
-		return
-	}
-
-	textures := []uint32{cube.Front, cube.Back, cube.Left, cube.Right, cube.Top, cube.Bottom}
-	for i, texture := range textures {
-		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
-		gl.BindTexture(gl.TEXTURE_2D, texture)
-	}
-
-	for i := 0; i < len(textures); i++ {
-		uniformName := fmt.Sprintf("textures[%d]", i)
-		loc := gl.GetUniformLocation(r.cubeProgram, gl.Str(uniformName+"\x00"))
-		gl.Uniform1i(loc, int32(i))
-	}
-
-	var VAO, VBO, EBO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.GenBuffers(1, &VBO)
-	gl.GenBuffers(1, &EBO)
-
-	gl.BindVertexArray(VAO)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 8*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 8*4, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 3, gl.FLOAT, false, 8*4, 5*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.DrawElementsWithOffset(gl.TRIANGLES, int32(len(indices)), gl.UNSIGNED_INT, 0)
+// SetShadows configures the sun's shadow map. Shadows are off until this is
+// called or a ToggleShadows message arrives.
+func (r *BlockRenderer) SetShadows(settings ShadowSettings) {
+	r.shadows.setSettings(settings)
+}
 
-	gl.BindVertexArray(0)
-	gl.DeleteVertexArrays(1, &VAO)
-	gl.DeleteBuffers(1, &VBO)
-	gl.DeleteBuffers(1, &EBO)
+// Metrics reports the geometry drawn last frame.
+func (r *BlockRenderer) Metrics() Metrics {
+	m := Metrics{}
+	m.Vertices, m.Triangles = r.batch.size()
+	return m
 }
 
 func (r *BlockRenderer) AddCube(cube primitive.Cube) {
 	r.cubes = append(r.cubes, cube)
+	r.dirty = true
 }
 
 func (r *BlockRenderer) drainEvents() {
 	for {
 		select {
 		case event := <-r.events:
-			if event == "ToggleWireframe" {
+			switch event {
+			case "ToggleWireframe":
 				r.ToggleWireframe()
 				logrus.Trace("Received ToggleWireframe event")
+			case "ToggleShadows":
+				r.shadows.toggle()
 			}
 		default:
 			return // Exit the loop when there are no more events
@@ -157,7 +132,10 @@ func (r *BlockRenderer) subscribeToEvents() {
 			if !ok {
 				return
 			}
-			if m.GetTopic() == "ToggleWireframe" {
+			if r.handleSceneMessage(m) {
+				continue
+			}
+			if m.GetTopic() == "ToggleWireframe" || m.GetTopic() == "ToggleShadows" {
 				r.events <- m.GetTopic()
 			}
 		default:
@@ -165,21 +143,20 @@ func (r *BlockRenderer) subscribeToEvents() {
 	}
 }
 
-func NewBlockRenderer() *BlockRenderer {
-	vertexShaderSource, err := shader.ShaderFS.ReadFile("block_vertex_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read vertex shader: %v", err)
-	}
-
-	fragmentShaderSource, err := shader.ShaderFS.ReadFile("block_fragment_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read fragment shader: %v", err)
-	}
-	cubeProgram, err := shader.NewProgram(string(vertexShaderSource)+"\x00", string(fragmentShaderSource)+"\x00")
+// newBlockProgram builds the textured block program and points its sampler
+// at texture unit 0.
+func newBlockProgram() uint32 {
+	program, err := shader.NewProgramFromFiles("block_vertex_shader.glsl", "block_fragment_shader.glsl")
 	if err != nil {
 		logrus.Fatalf("failed to create program: %v", err)
 	}
-	gl.UseProgram(cubeProgram)
+	gl.UseProgram(program)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("blockTexture\x00")), 0)
+	return program
+}
+
+func NewBlockRenderer() *BlockRenderer {
+	cubeProgram := newBlockProgram()
 
 	gl.Enable(gl.CULL_FACE)
 	gl.CullFace(gl.BACK)
@@ -190,5 +167,6 @@ func NewBlockRenderer() *BlockRenderer {
 		cubeProgram: cubeProgram,
 		cubes:       []primitive.Cube{},
 		events:      make(chan string),
+		sceneState:  newSceneState(),
 	}
 }
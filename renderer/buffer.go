@@ -0,0 +1,62 @@
+package renderer
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// slicePtr is gl.Ptr for slices that may be empty.
+func slicePtr[T any](data []T) unsafe.Pointer {
+	if len(data) == 0 {
+		return nil
+	}
+	return gl.Ptr(data)
+}
+
+// meshBuffers holds a mesher's GL objects. Uploads reuse them once created,
+// so remeshing doesn't leak buffers.
+type meshBuffers struct {
+	vao uint32
+	vbo uint32
+	ebo uint32
+}
+
+func (b *meshBuffers) upload(vertices []float32, layout VertexLayout) {
+	if b.vao == 0 {
+		gl.GenVertexArrays(1, &b.vao)
+		gl.GenBuffers(1, &b.vbo)
+		gl.BindVertexArray(b.vao)
+		gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+		layout.Enable()
+	} else {
+		gl.BindVertexArray(b.vao)
+		gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	}
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, slicePtr(vertices), gl.STATIC_DRAW)
+	gl.BindVertexArray(0)
+}
+
+func (b *meshBuffers) uploadIndexed(vertices []float32, indices []uint32, layout VertexLayout, usage uint32) {
+	if b.ebo == 0 {
+		gl.GenBuffers(1, &b.ebo)
+	}
+	b.upload(vertices, layout)
+	gl.BindVertexArray(b.vao)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, b.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, slicePtr(indices), usage)
+	gl.BindVertexArray(0)
+}
+
+// Delete frees the GL objects. The mesher can be uploaded again afterwards.
+func (b *meshBuffers) Delete() {
+	if b.vao == 0 {
+		return
+	}
+	gl.DeleteVertexArrays(1, &b.vao)
+	gl.DeleteBuffers(1, &b.vbo)
+	if b.ebo != 0 {
+		gl.DeleteBuffers(1, &b.ebo)
+	}
+	*b = meshBuffers{}
+}
@@ -1,11 +1,8 @@
 package renderer
 
 import (
-	"fmt"
-
 	"github.com/dfirebaugh/cube/pkg/message"
 	"github.com/dfirebaugh/cube/pkg/primitive"
-	"github.com/dfirebaugh/cube/shader"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/sirupsen/logrus"
@@ -17,8 +14,13 @@ type ChunkRenderer struct {
 	messageBus  message.MessageBus
 	cubeProgram uint32
 	chunk       *primitive.Chunk
+	batch       textureBatch
+	dirty       bool
 	wireframe   bool
 	events      chan string
+	shadows     shadowPass
+	sceneState
+	stats CullStats
 }
 
 func (r *ChunkRenderer) SetCamera(camera Camera) {
@@ -51,108 +53,123 @@ func (r *ChunkRenderer) Render() {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 	}
 
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	if !viewFrustum(r.camera, r.window).IntersectsAABB(r.bounds()) {
+		r.stats = CullStats{Culled: 1}
+		r.drainEvents()
+		return
+	}
+	r.stats = CullStats{Visible: 1}
+
+	if r.dirty {
+		r.batch.build(r.exposedCubes())
+		r.dirty = false
+	}
+
+	model := r.model()
+	if r.shadows.enabled() {
+		r.shadows.renderBatch(r.camera, r.window, r.Lighting().SunDirection, &r.batch, model)
+	}
+
+	gl.UseProgram(r.cubeProgram)
 
 	view := r.camera.GetViewMatrix()
-	windowWidth, windowHeight := r.window.GetSize()
-	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/float32(windowHeight), 0.1, 100.0)
+	projection := perspective(r.camera, r.window)
 
 	viewLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("view\x00"))
 	projLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("projection\x00"))
 
 	gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
 	gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
+	r.setSceneUniforms(r.cubeProgram, &r.shadows, r.camera.GetPosition())
 
-	chunkPos := r.chunk.WorldPosition()
-	for x := 0; x < primitive.ChunkSize; x++ {
-		for y := 0; y < primitive.ChunkSize; y++ {
-			for z := 0; z < primitive.ChunkSize; z++ {
-				cube := r.chunk.GetBlock(x, y, z)
-				if cube.Size > 0 {
-					cube.Position.X = chunkPos.X() + float32(x)
-					cube.Position.Y = chunkPos.Y() + float32(y)
-					cube.Position.Z = chunkPos.Z() + float32(z)
-
-					cube.HideLeft = !r.chunk.IsFaceExposed(x, y, z, "left")
-					cube.HideRight = !r.chunk.IsFaceExposed(x, y, z, "right")
-					cube.HideBottom = !r.chunk.IsFaceExposed(x, y, z, "bottom")
-					cube.HideTop = !r.chunk.IsFaceExposed(x, y, z, "top")
-					cube.HideBack = !r.chunk.IsFaceExposed(x, y, z, "back")
-					cube.HideFront = !r.chunk.IsFaceExposed(x, y, z, "front")
-
-					model := mgl32.Translate3D(cube.Position.X, cube.Position.Y, cube.Position.Z)
-					modelLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("model\x00"))
-					gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
-
-					r.renderCube(cube)
-				}
-			}
-		}
-	}
+	modelLoc := gl.GetUniformLocation(r.cubeProgram, gl.Str("model\x00"))
+	gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
+
+	r.batch.draw()
 	r.drainEvents()
 }
 
-func (r *ChunkRenderer) renderCube(cube primitive.Cube) {
-	vertices := cube.Vertices()
-	indices := cube.Indices(0)
-
-	if len(vertices) == 0 || len(indices) == 0 {
-		return
-	}
-
-	textures := []uint32{cube.Front, cube.Back, cube.Left, cube.Right, cube.Top, cube.Bottom}
-	for i, texture := range textures {
-		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
-		gl.BindTexture(gl.TEXTURE_2D, texture)
-	}
-
-	for i := 0; i < len(textures); i++ {
-		uniformName := fmt.Sprintf("textures[%d]", i)
-		loc := gl.GetUniformLocation(r.cubeProgram, gl.Str(uniformName+"\x00"))
-		gl.Uniform1i(loc, int32(i))
-	}
-
-	var VAO, VBO, EBO uint32
-	gl.GenVertexArrays(1, &VAO)
-	gl.GenBuffers(1, &VBO)
-	gl.GenBuffers(1, &EBO)
-
-	gl.BindVertexArray(VAO)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+// SetShadows configures the sun's shadow map. Shadows are off until this is
+// called or a ToggleShadows message arrives.
+func (r *ChunkRenderer) SetShadows(settings ShadowSettings) {
+	r.shadows.setSettings(settings)
+}
 
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 8*4, 0)
-	gl.EnableVertexAttribArray(0)
+// model places the chunk in the world.
+func (r *ChunkRenderer) model() mgl32.Mat4 {
+	pos := r.chunk.WorldPosition()
+	return mgl32.Translate3D(pos[0], pos[1], pos[2])
+}
 
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 8*4, 3*4)
-	gl.EnableVertexAttribArray(1)
+// bounds is the chunk's cells in world space. The batch centres cubes on
+// their positions, so the cube at x fills [x-0.5, x+0.5].
+func (r *ChunkRenderer) bounds() primitive.AABB {
+	return cellBounds(r.chunk.WorldPosition().Sub(mgl32.Vec3{0.5, 0.5, 0.5}), primitive.ChunkSize)
+}
 
-	gl.VertexAttribPointerWithOffset(2, 3, gl.FLOAT, false, 8*4, 5*4)
-	gl.EnableVertexAttribArray(2)
+// CullStats reports whether the chunk was drawn or culled last frame.
+func (r *ChunkRenderer) CullStats() CullStats {
+	return r.stats
+}
 
-	gl.DrawElementsWithOffset(gl.TRIANGLES, int32(len(indices)), gl.UNSIGNED_INT, 0)
+// Metrics reports the chunk's geometry when it was drawn last frame.
+func (r *ChunkRenderer) Metrics() Metrics {
+	m := Metrics{Sections: 1, Cull: r.stats}
+	if r.stats.Visible > 0 {
+		m.Vertices, m.Triangles = r.batch.size()
+	}
+	return m
+}
 
-	gl.BindVertexArray(0)
-	gl.DeleteVertexArrays(1, &VAO)
-	gl.DeleteBuffers(1, &VBO)
-	gl.DeleteBuffers(1, &EBO)
+// SectionBounds outlines the chunk.
+func (r *ChunkRenderer) SectionBounds() []primitive.AABB {
+	return []primitive.AABB{r.bounds()}
 }
 
 func (r *ChunkRenderer) SetBlock(x, y, z int, cube primitive.Cube) {
 	r.chunk.SetBlock(x, y, z, cube)
+	r.dirty = true
+}
+
+// exposedCubes returns the chunk's cubes in chunk space with faces against
+// solid neighbours hidden.
+func (r *ChunkRenderer) exposedCubes() []primitive.Cube {
+	var cubes []primitive.Cube
+	for x := 0; x < primitive.ChunkSize; x++ {
+		for y := 0; y < primitive.ChunkSize; y++ {
+			for z := 0; z < primitive.ChunkSize; z++ {
+				cube := r.chunk.GetBlock(x, y, z)
+				if cube.Size == 0 {
+					continue
+				}
+				cube.Position.X = float32(x)
+				cube.Position.Y = float32(y)
+				cube.Position.Z = float32(z)
+
+				cube.HideLeft = !r.chunk.IsFaceExposed(x, y, z, "left")
+				cube.HideRight = !r.chunk.IsFaceExposed(x, y, z, "right")
+				cube.HideBottom = !r.chunk.IsFaceExposed(x, y, z, "bottom")
+				cube.HideTop = !r.chunk.IsFaceExposed(x, y, z, "top")
+				cube.HideBack = !r.chunk.IsFaceExposed(x, y, z, "back")
+				cube.HideFront = !r.chunk.IsFaceExposed(x, y, z, "front")
+
+				cubes = append(cubes, cube)
+			}
+		}
+	}
+	return cubes
 }
 
 func (r *ChunkRenderer) drainEvents() {
 	for {
 		select {
 		case event := <-r.events:
-			if event == "ToggleWireframe" {
+			switch event {
+			case "ToggleWireframe":
 				r.ToggleWireframe()
 				logrus.Trace("Received ToggleWireframe event")
+			case "ToggleShadows":
+				r.shadows.toggle()
 			}
 		default:
 			return // Exit the loop when there are no more events
@@ -175,7 +192,10 @@ func (r *ChunkRenderer) subscribeToEvents() {
 			if !ok {
 				return
 			}
-			if m.GetTopic() == "ToggleWireframe" {
+			if r.handleSceneMessage(m) {
+				continue
+			}
+			if m.GetTopic() == "ToggleWireframe" || m.GetTopic() == "ToggleShadows" {
 				r.events <- m.GetTopic()
 			}
 		default:
@@ -184,20 +204,7 @@ func (r *ChunkRenderer) subscribeToEvents() {
 }
 
 func NewChunkRenderer(position mgl32.Vec3) *ChunkRenderer {
-	vertexShaderSource, err := shader.ShaderFS.ReadFile("block_vertex_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read vertex shader: %v", err)
-	}
-
-	fragmentShaderSource, err := shader.ShaderFS.ReadFile("block_fragment_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read fragment shader: %v", err)
-	}
-	cubeProgram, err := shader.NewProgram(string(vertexShaderSource)+"\x00", string(fragmentShaderSource)+"\x00")
-	if err != nil {
-		logrus.Fatalf("failed to create program: %v", err)
-	}
-	gl.UseProgram(cubeProgram)
+	cubeProgram := newBlockProgram()
 
 	gl.Enable(gl.CULL_FACE)
 	gl.CullFace(gl.BACK)
@@ -208,5 +215,6 @@ func NewChunkRenderer(position mgl32.Vec3) *ChunkRenderer {
 		cubeProgram: cubeProgram,
 		chunk:       primitive.NewChunk(position),
 		events:      make(chan string),
+		sceneState:  newSceneState(),
 	}
 }
@@ -0,0 +1,50 @@
+package renderer
+
+import (
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CullStats counts what the last frame drew, what frustum culling skipped
+// and what occlusion culling skipped inside the frustum.
+type CullStats struct {
+	Visible  int
+	Culled   int
+	Occluded int
+}
+
+// DefaultViewDistance is the far plane renderers draw to for cameras that
+// don't set their own.
+const DefaultViewDistance = 100
+
+// ViewDistanceCamera is a Camera that sets how far renderers draw, which
+// also bounds occlusion culling. Fog isn't refitted; see FogForViewDistance.
+type ViewDistanceCamera interface {
+	Camera
+	ViewDistance() float32
+}
+
+// viewDistance is the far plane renderers draw camera's view to.
+func viewDistance(camera Camera) float32 {
+	if c, ok := camera.(ViewDistanceCamera); ok && c.ViewDistance() > 0 {
+		return c.ViewDistance()
+	}
+	return DefaultViewDistance
+}
+
+// Projection is the projection renderers draw with at aspect, out to
+// distance.
+func Projection(aspect, distance float32) mgl32.Mat4 {
+	return mgl32.Perspective(mgl32.DegToRad(45), aspect, 0.1, distance)
+}
+
+// perspective is the projection renderers draw camera's view with.
+func perspective(camera Camera, window Window) mgl32.Mat4 {
+	width, height := window.GetSize()
+	return Projection(float32(width)/float32(height), viewDistance(camera))
+}
+
+// viewFrustum is the camera's frustum in world space.
+func viewFrustum(camera Camera, window Window) primitive.Frustum {
+	return primitive.NewFrustum(perspective(camera, window).Mul4(camera.GetViewMatrix()))
+}
@@ -0,0 +1,292 @@
+package renderer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// DebugLine is one line segment drawn by a DebugRenderer. A Lifetime of
+// zero or less draws it for the next frame only; otherwise it is drawn
+// every frame until the lifetime has passed.
+type DebugLine struct {
+	From, To mgl32.Vec3
+	Color    component.Color
+	Lifetime time.Duration
+}
+
+type debugLine struct {
+	DebugLine
+	expires time.Time
+}
+
+// DebugDraw queues lines for a DebugRenderer. It is safe to call from any
+// goroutine.
+type DebugDraw struct {
+	mu    sync.Mutex
+	lines []debugLine
+}
+
+func NewDebugDraw() *DebugDraw {
+	return &DebugDraw{}
+}
+
+// Add queues lines as they are.
+func (d *DebugDraw) Add(lines ...DebugLine) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, l := range lines {
+		d.lines = append(d.lines, debugLine{DebugLine: l, expires: now.Add(l.Lifetime)})
+	}
+}
+
+func (d *DebugDraw) DrawLine(from, to mgl32.Vec3, color component.Color, lifetime time.Duration) {
+	d.Add(DebugLine{From: from, To: to, Color: color, Lifetime: lifetime})
+}
+
+// DrawRay draws length units from origin along direction.
+func (d *DebugDraw) DrawRay(origin, direction mgl32.Vec3, length float32, color component.Color, lifetime time.Duration) {
+	if direction.Len() == 0 {
+		return
+	}
+	d.DrawLine(origin, origin.Add(direction.Normalize().Mul(length)), color, lifetime)
+}
+
+// DrawAABB draws the twelve edges of box.
+func (d *DebugDraw) DrawAABB(box primitive.AABB, color component.Color, lifetime time.Duration) {
+	lines := make([]DebugLine, 0, 12)
+	for _, edge := range boxEdges(box) {
+		lines = append(lines, DebugLine{From: edge[0], To: edge[1], Color: color, Lifetime: lifetime})
+	}
+	d.Add(lines...)
+}
+
+// DrawAxis draws the x, y and z axes from origin in red, green and blue.
+func (d *DebugDraw) DrawAxis(origin mgl32.Vec3, size float32, lifetime time.Duration) {
+	d.Add(
+		DebugLine{From: origin, To: origin.Add(mgl32.Vec3{size, 0, 0}), Color: component.Color{1, 0, 0}, Lifetime: lifetime},
+		DebugLine{From: origin, To: origin.Add(mgl32.Vec3{0, size, 0}), Color: component.Color{0, 1, 0}, Lifetime: lifetime},
+		DebugLine{From: origin, To: origin.Add(mgl32.Vec3{0, 0, size}), Color: component.Color{0, 0, 1}, Lifetime: lifetime},
+	)
+}
+
+// DrawNormals draws each triangle's normal from its centre, placed in the
+// world by transform, the mesh's model matrix. It reads the mesher's
+// position and normal attributes, so meshers without float positions and
+// normals, such as PackedMesher, draw nothing. MeshRenderer.DrawNormals
+// draws every section this way.
+func (d *DebugDraw) DrawNormals(m Mesher, transform mgl32.Mat4, length float32, color component.Color, lifetime time.Duration) {
+	layout := m.VertexLayout()
+	position, okPosition := layout.Attribute("position")
+	normal, okNormal := layout.Attribute("normal")
+	if !okPosition || !okNormal || position.Type != gl.FLOAT || normal.Type != gl.FLOAT {
+		return
+	}
+	stride := layout.Floats()
+	vertices, indices := m.GetMesh()
+	if stride == 0 {
+		return
+	}
+	if indices == nil {
+		indices = make([]uint32, len(vertices)/stride)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	vec := func(vertex uint32, a VertexAttribute) mgl32.Vec3 {
+		i := int(vertex)*stride + a.Offset/4
+		return mgl32.Vec3{vertices[i], vertices[i+1], vertices[i+2]}
+	}
+	rotation := transform.Mat3()
+	lines := make([]DebugLine, 0, len(indices)/3)
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := indices[i], indices[i+1], indices[i+2]
+		centre := vec(a, position).Add(vec(b, position)).Add(vec(c, position)).Mul(1.0 / 3)
+		centre = transform.Mul4x1(centre.Vec4(1)).Vec3()
+		n := rotation.Mul3x1(vec(a, normal))
+		if n.Len() == 0 {
+			continue
+		}
+		lines = append(lines, DebugLine{From: centre, To: centre.Add(n.Normalize().Mul(length)), Color: color, Lifetime: lifetime})
+	}
+	d.Add(lines...)
+}
+
+// Clear drops every queued line, timed or not.
+func (d *DebugDraw) Clear() {
+	d.mu.Lock()
+	d.lines = d.lines[:0]
+	d.mu.Unlock()
+}
+
+// Len is how many lines are queued.
+func (d *DebugDraw) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.lines)
+}
+
+// vertices returns the lines to draw at now in PositionColorLayout, and
+// drops single frame and expired lines.
+func (d *DebugDraw) vertices(now time.Time) []float32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data := make([]float32, 0, len(d.lines)*12)
+	kept := d.lines[:0]
+	for _, l := range d.lines {
+		if l.Lifetime > 0 && !now.Before(l.expires) {
+			continue
+		}
+		c := l.Color
+		data = append(data,
+			l.From[0], l.From[1], l.From[2], c[0], c[1], c[2],
+			l.To[0], l.To[1], l.To[2], c[0], c[1], c[2],
+		)
+		if l.Lifetime > 0 {
+			kept = append(kept, l)
+		}
+	}
+	d.lines = kept
+	return data
+}
+
+// boxEdges returns the twelve edges of box.
+func boxEdges(box primitive.AABB) [12][2]mgl32.Vec3 {
+	var corners [8]mgl32.Vec3
+	for i := range corners {
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) != 0 {
+				corners[i][axis] = box.Max[axis]
+			} else {
+				corners[i][axis] = box.Min[axis]
+			}
+		}
+	}
+	var edges [12][2]mgl32.Vec3
+	n := 0
+	for i := range corners {
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) == 0 {
+				edges[n] = [2]mgl32.Vec3{corners[i], corners[i|1<<axis]}
+				n++
+			}
+		}
+	}
+	return edges
+}
+
+// SectionReporter is a renderer that can outline the sections or chunks it
+// has loaded. Each box covers the cells its cubes are drawn in. Call
+// SectionBounds on the render thread.
+type SectionReporter interface {
+	SectionBounds() []primitive.AABB
+}
+
+// cellBounds is the box around size³ cells starting at origin.
+func cellBounds(origin mgl32.Vec3, size float32) primitive.AABB {
+	return primitive.AABB{Min: origin, Max: origin.Add(mgl32.Vec3{size, size, size})}
+}
+
+// DebugRenderer draws a DebugDraw's lines after the world. Other
+// goroutines can queue lines through the DebugDraw topic, with a DebugLine
+// or []DebugLine payload.
+type DebugRenderer struct {
+	program   uint32
+	camera    Camera
+	window    Window
+	bus       message.MessageBus
+	draw      *DebugDraw
+	depthTest bool
+	buffers   meshBuffers
+}
+
+func NewDebugRenderer() *DebugRenderer {
+	program, err := shader.NewProgramFromFiles("debug_line_vertex_shader.glsl", "debug_line_fragment_shader.glsl")
+	if err != nil {
+		logrus.Fatalln("failed to create debug line program:", err)
+	}
+	return &DebugRenderer{program: program, draw: NewDebugDraw(), depthTest: true}
+}
+
+func (r *DebugRenderer) SetCamera(camera Camera) {
+	r.camera = camera
+}
+
+func (r *DebugRenderer) SetWindow(window Window) {
+	r.window = window
+}
+
+func (r *DebugRenderer) SetMessageBus(m message.MessageBus) {
+	r.bus = m
+	go r.subscribeToEvents()
+}
+
+// Draw is where lines are queued.
+func (r *DebugRenderer) Draw() *DebugDraw {
+	return r.draw
+}
+
+// SetDepthTest hides lines behind the world when on, which is the default.
+// Turn it off to see lines through walls.
+func (r *DebugRenderer) SetDepthTest(enabled bool) {
+	r.depthTest = enabled
+}
+
+func (r *DebugRenderer) Render() {
+	vertices := r.draw.vertices(time.Now())
+	if len(vertices) == 0 || r.camera == nil || r.window == nil {
+		return
+	}
+
+	if !r.depthTest {
+		gl.Disable(gl.DEPTH_TEST)
+	}
+	gl.UseProgram(r.program)
+	view := r.camera.GetViewMatrix()
+	projection := perspective(r.camera, r.window)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("view\x00")), 1, false, &view[0])
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("projection\x00")), 1, false, &projection[0])
+
+	r.buffers.upload(vertices, PositionColorLayout)
+	gl.BindVertexArray(r.buffers.vao)
+	gl.DrawArrays(gl.LINES, 0, int32(len(vertices)/PositionColorLayout.Floats()))
+	gl.BindVertexArray(0)
+
+	if !r.depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	checkGLError("DebugRenderer")
+}
+
+func (r *DebugRenderer) subscribeToEvents() {
+	if r.bus == nil {
+		logrus.Println("MessageBus not set for DebugRenderer")
+		return
+	}
+
+	msg := r.bus.Subscribe()
+	defer r.bus.Unsubscribe(msg)
+
+	for m := range msg {
+		if m.GetTopic() != "DebugDraw" {
+			continue
+		}
+		switch payload := m.GetPayload().(type) {
+		case DebugLine:
+			r.draw.Add(payload)
+		case []DebugLine:
+			r.draw.Add(payload...)
+		default:
+			logrus.Warnf("ignoring DebugDraw with payload %T", m.GetPayload())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestDebugDrawLifetime(t *testing.T) {
+	d := NewDebugDraw()
+	red := component.Color{1, 0, 0}
+	d.DrawLine(mgl32.Vec3{}, mgl32.Vec3{1, 0, 0}, red, 0)
+	d.DrawRay(mgl32.Vec3{}, mgl32.Vec3{0, 2, 0}, 3, red, time.Minute)
+
+	now := time.Now()
+	data := d.vertices(now)
+	if got := len(data) / PositionColorLayout.Floats(); got != 4 {
+		t.Fatalf("first frame drew %d vertices, want 4", got)
+	}
+	if end := (mgl32.Vec3{data[18], data[19], data[20]}); !end.ApproxEqual(mgl32.Vec3{0, 3, 0}) {
+		t.Errorf("ray ends at %v, want 3 units up", end)
+	}
+
+	if got := len(d.vertices(now)) / PositionColorLayout.Floats(); got != 2 {
+		t.Errorf("second frame drew %d vertices, want only the timed ray", got)
+	}
+	if d.vertices(now.Add(2 * time.Minute)); d.Len() != 0 {
+		t.Errorf("%d lines left after the ray expired", d.Len())
+	}
+}
+
+func TestDebugDrawAABB(t *testing.T) {
+	d := NewDebugDraw()
+	box := primitive.AABB{Min: mgl32.Vec3{0, 0, 0}, Max: mgl32.Vec3{1, 2, 3}}
+	d.DrawAABB(box, component.Color{1, 1, 1}, 0)
+	if d.Len() != 12 {
+		t.Fatalf("box has %d edges, want 12", d.Len())
+	}
+
+	lengths := map[float32]int{}
+	for _, l := range d.lines {
+		lengths[l.To.Sub(l.From).Len()]++
+	}
+	if lengths[1] != 4 || lengths[2] != 4 || lengths[3] != 4 {
+		t.Errorf("edge lengths = %v, want four of each side", lengths)
+	}
+}
+
+func TestDebugDrawNormals(t *testing.T) {
+	m := NewCubeMesher()
+	m.GenerateMesh([]primitive.Cube{{Size: 1}})
+	d := NewDebugDraw()
+	d.DrawNormals(m, mgl32.Ident4(), 0.5, component.Color{1, 1, 0}, 0)
+	if d.Len() != 12 {
+		t.Fatalf("got %d normals, want one per triangle", d.Len())
+	}
+	for _, l := range d.lines {
+		// Each normal starts on a face and points away from the cube.
+		if l.To.Len() <= l.From.Len() {
+			t.Errorf("normal %v -> %v points inwards", l.From, l.To)
+		}
+	}
+}
+
+func TestDebugDrawNormalsTransform(t *testing.T) {
+	m := NewGreedyMesher()
+	m.GenerateMesh([]primitive.Cube{{Size: 1}})
+	d := NewDebugDraw()
+	offset := mgl32.Vec3{16, 0, -32}
+	d.DrawNormals(m, mgl32.Translate3D(offset[0], offset[1], offset[2]), 0.5, component.Color{1, 1, 0}, 0)
+	if d.Len() == 0 {
+		t.Fatal("no normals drawn")
+	}
+	centre := offset.Add(mgl32.Vec3{0.5, 0.5, 0.5})
+	for _, l := range d.lines {
+		// Each normal starts on a face of the moved cube and points away
+		// from it.
+		from, to := l.From.Sub(centre), l.To.Sub(centre)
+		for i := 0; i < 3; i++ {
+			if mgl32.Abs(from[i]) > 0.5+1e-5 {
+				t.Fatalf("normal starts at %v, off the cube moved to %v", l.From, offset)
+			}
+		}
+		if to.Len() <= from.Len() {
+			t.Errorf("normal %v -> %v points inwards", l.From, l.To)
+		}
+	}
+}
+
+// Each renderer outlines the cells it draws its cubes in: MeshRenderer
+// fills [x, x+1] and ChunkRenderer centres cubes on their positions.
+func TestSectionBoundsCoverDrawnCells(t *testing.T) {
+	chunk := &ChunkRenderer{chunk: primitive.NewChunk(mgl32.Vec3{16, 0, -16})}
+	mesh := &MeshRenderer{sections: map[sectionKey]*meshSection{{1, 0, -1}: newMeshSection(sectionKey{1, 0, -1}, NewGreedyMesher())}}
+	half := mgl32.Vec3{0.5, 0.5, 0.5}
+	if a, b := chunk.SectionBounds(), mesh.SectionBounds(); len(a) != 1 || len(b) != 1 ||
+		a[0].Min != b[0].Min.Sub(half) || a[0].Max != b[0].Max.Sub(half) {
+		t.Fatalf("chunk bounds %v, section bounds %v, want the chunk's half a cube lower", a, b)
+	}
+
+	// The chunk draws the cube in its cell 3, 4, 5 over exactly that cell.
+	bounds := chunk.SectionBounds()[0]
+	cell := primitive.AABB{Min: bounds.Min.Add(mgl32.Vec3{3, 4, 5}), Max: bounds.Min.Add(mgl32.Vec3{4, 5, 6})}
+	cube := primitive.Cube{Size: 1}
+	cube.X, cube.Y, cube.Z = 3, 4, 5
+	vertices := cube.Vertices()
+	model := chunk.model()
+	for i := 0; i < len(vertices); i += 8 {
+		p := model.Mul4x1(mgl32.Vec4{vertices[i] + cube.X, vertices[i+1] + cube.Y, vertices[i+2] + cube.Z, 1}).Vec3()
+		for axis := 0; axis < 3; axis++ {
+			if p[axis] < cell.Min[axis]-1e-5 || p[axis] > cell.Max[axis]+1e-5 {
+				t.Fatalf("vertex %v outside the cell %v", p, cell)
+			}
+		}
+	}
+}
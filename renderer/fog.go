@@ -0,0 +1,129 @@
+package renderer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+type FogMode int
+
+const (
+	FogOff FogMode = iota
+	// FogLinear fades from Start to End.
+	FogLinear
+	// FogExponential thickens by Density per unit of distance.
+	FogExponential
+)
+
+// Fog blends distant surfaces into Color, which should match the sky's
+// horizon. Fog follows the sky: a SetSky message also sets Color.
+//
+// It can be changed over the message bus with these topics:
+//
+//	SetFog  Fog
+//	SetSky  Sky
+type Fog struct {
+	Mode       FogMode
+	Start, End float32
+	Density    float32
+	Color      component.Color
+}
+
+// DefaultFog is fitted to DefaultViewDistance so geometry fades out before
+// it is clipped.
+func DefaultFog() Fog {
+	return FogForViewDistance(FogLinear, DefaultViewDistance)
+}
+
+// FogForViewDistance returns fog that is complete at distance, so nothing
+// beyond it shows.
+func FogForViewDistance(mode FogMode, distance float32) Fog {
+	return Fog{
+		Mode:  mode,
+		Start: distance * 0.6,
+		End:   distance,
+		// exp(-4) leaves under 2% of a surface's colour at distance.
+		Density: 4 / distance,
+		Color:   DefaultSky().Horizon,
+	}
+}
+
+// Amount mirrors fogAmount in fog.glsl. It returns how much of a surface at
+// distance is replaced by fog, from 0 to 1.
+func (f Fog) Amount(distance float32) float32 {
+	var amount float64
+	switch f.Mode {
+	case FogLinear:
+		if f.End <= f.Start {
+			return 0
+		}
+		amount = float64((distance - f.Start) / (f.End - f.Start))
+	case FogExponential:
+		amount = 1 - math.Exp(-float64(f.Density*distance))
+	default:
+		return 0
+	}
+	return float32(math.Min(math.Max(amount, 0), 1))
+}
+
+// setUniforms uploads the fog to a program that includes fog.glsl. eye is
+// the camera position distances are measured from.
+func (f Fog) setUniforms(program uint32, eye mgl32.Vec3) {
+	color := mgl32.Vec3(f.Color)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("fogMode\x00")), int32(f.Mode))
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("fogStart\x00")), f.Start)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("fogEnd\x00")), f.End)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("fogDensity\x00")), f.Density)
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("fogColor\x00")), 1, &color[0])
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("cameraPosition\x00")), 1, &eye[0])
+}
+
+// fogState is embedded in sceneState. Bus updates arrive on
+// another goroutine, so access is locked.
+type fogState struct {
+	mu  sync.Mutex
+	fog Fog
+}
+
+func (s *fogState) SetFog(f Fog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fog = f
+}
+
+func (s *fogState) Fog() Fog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fog
+}
+
+// handleFogMessage applies a fog topic and reports whether m was one.
+func (s *fogState) handleFogMessage(m message.Request) bool {
+	switch m.GetTopic() {
+	case "SetFog":
+		f, ok := m.GetPayload().(Fog)
+		if !ok {
+			logrus.Warnf("ignoring SetFog with payload %T", m.GetPayload())
+			return true
+		}
+		s.SetFog(f)
+	case "SetSky":
+		sky, ok := m.GetPayload().(Sky)
+		if !ok {
+			// The sky renderer reports bad payloads.
+			return true
+		}
+		s.mu.Lock()
+		s.fog.Color = sky.Horizon
+		s.mu.Unlock()
+	default:
+		return false
+	}
+	return true
+}
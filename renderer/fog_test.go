@@ -0,0 +1,127 @@
+package renderer
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFogAmount(t *testing.T) {
+	linear := Fog{Mode: FogLinear, Start: 10, End: 20}
+	exponential := Fog{Mode: FogExponential, Density: 0.1}
+	tests := []struct {
+		name     string
+		fog      Fog
+		distance float32
+		want     float32
+	}{
+		{"off", Fog{Start: 0, End: 1}, 50, 0},
+		{"linear before start", linear, 5, 0},
+		{"linear halfway", linear, 15, 0.5},
+		{"linear past end", linear, 40, 1},
+		{"linear empty range", Fog{Mode: FogLinear, Start: 10, End: 10}, 40, 0},
+		{"exponential at camera", exponential, 0, 0},
+		{"exponential", exponential, 10, 0.6321},
+	}
+	for _, tt := range tests {
+		if got := tt.fog.Amount(tt.distance); got < tt.want-1e-3 || got > tt.want+1e-3 {
+			t.Errorf("%s: Amount(%v) = %v, want %v", tt.name, tt.distance, got, tt.want)
+		}
+	}
+}
+
+func TestFogForViewDistanceIsCompleteAtDistance(t *testing.T) {
+	for _, mode := range []FogMode{FogLinear, FogExponential} {
+		if got := FogForViewDistance(mode, 64).Amount(64); got < 0.98 {
+			t.Errorf("mode %d: Amount at view distance = %v, want nearly 1", mode, got)
+		}
+	}
+}
+
+func TestSkyAt(t *testing.T) {
+	sky := Sky{Horizon: component.Color{1, 0, 0}, Zenith: component.Color{0, 0, 1}}
+	if got := sky.At(mgl32.Vec3{0, 1, 0}); got != mgl32.Vec3(sky.Zenith) {
+		t.Errorf("straight up = %v, want the zenith", got)
+	}
+	if got := sky.At(mgl32.Vec3{1, 0, 0}); got != mgl32.Vec3(sky.Horizon) {
+		t.Errorf("straight out = %v, want the horizon", got)
+	}
+	if got := sky.At(mgl32.Vec3{0, -1, 0}); got != mgl32.Vec3(sky.Horizon) {
+		t.Errorf("straight down = %v, want the horizon", got)
+	}
+}
+
+func TestSoftwareRendererFadesDistantCubesIntoFog(t *testing.T) {
+	render := func(eye mgl32.Vec3) color.RGBA {
+		r := NewSoftwareRenderer(NewGreedyMesher(), 32, 32)
+		r.SetSky(FlatSky(component.Color{1, 1, 1}))
+		r.SetCamera(testCamera{eye: eye, target: mgl32.Vec3{4, 4, 4}})
+		for _, cube := range scene.Solid(8) {
+			r.AddCube(cube)
+		}
+		r.Render()
+		return r.Image().RGBAAt(16, 16)
+	}
+
+	white := color.RGBA{255, 255, 255, 255}
+	if near := render(mgl32.Vec3{4, 4, 20}); near == white {
+		t.Error("a nearby cube shouldn't be fogged")
+	}
+	if far := render(mgl32.Vec3{4, 4, 8 + DefaultViewDistance*0.98}); far.R < 240 || far.G < 240 || far.B < 240 {
+		t.Errorf("a cube near the view distance = %v, want close to the fog colour", far)
+	}
+}
+
+// farCamera sets its own view distance.
+type farCamera struct {
+	testCamera
+	distance float32
+}
+
+func (c farCamera) ViewDistance() float32 {
+	return c.distance
+}
+
+func TestCameraViewDistanceDrivesProjectionAndCulling(t *testing.T) {
+	if got := viewDistance(testCamera{}); got != DefaultViewDistance {
+		t.Errorf("a camera without a view distance sees %v, want %v", got, DefaultViewDistance)
+	}
+
+	camera := farCamera{distance: 200}
+	if got := viewDistance(camera); got != 200 {
+		t.Errorf("viewDistance = %v, want 200", got)
+	}
+	if got := occlusionDistance(viewDistance(camera)); got != 200/sectionSize+1 {
+		t.Errorf("occlusionDistance = %d, want %d", got, 200/sectionSize+1)
+	}
+	// A point just inside the far plane is inside the clip volume.
+	clip := Projection(1, viewDistance(camera)).Mul4x1(mgl32.Vec4{0, 0, -199, 1})
+	if z := clip.Z() / clip.W(); z > 1 {
+		t.Errorf("a point at 199 is clipped with a view distance of 200 (ndc z %v)", z)
+	}
+
+	// Renderers draw out to the camera's view distance, which fog doesn't
+	// change.
+	render := func(camera Camera) color.RGBA {
+		r := NewSoftwareRenderer(NewGreedyMesher(), 32, 32)
+		r.SetSky(FlatSky(component.Color{1, 1, 1}))
+		r.SetFog(Fog{Mode: FogLinear, Start: 100, End: 250})
+		r.SetCamera(camera)
+		for _, cube := range scene.Solid(8) {
+			r.AddCube(cube)
+		}
+		r.Render()
+		return r.Image().RGBAAt(16, 16)
+	}
+	eye := testCamera{eye: mgl32.Vec3{4, 4, 158}, target: mgl32.Vec3{4, 4, 4}}
+	white := color.RGBA{255, 255, 255, 255}
+	if got := render(eye); got != white {
+		t.Errorf("a cube past the default view distance = %v, want it clipped", got)
+	}
+	if got := render(farCamera{testCamera: eye, distance: 200}); got == white {
+		t.Error("a cube inside the camera's view distance was clipped")
+	}
+}
@@ -0,0 +1,113 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Framebuffer is an offscreen colour and depth target. Rendering between
+// Bind and Unbind goes to it instead of the window.
+type Framebuffer struct {
+	fbo, color, depth uint32
+	width, height     int
+}
+
+func NewFramebuffer(width, height int) (*Framebuffer, error) {
+	f := &Framebuffer{width: width, height: height}
+
+	gl.GenFramebuffers(1, &f.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+
+	gl.GenRenderbuffers(1, &f.color)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.color)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, f.color)
+
+	gl.GenRenderbuffers(1, &f.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, f.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		f.Delete()
+		return nil, fmt.Errorf("framebuffer incomplete: 0x%x", status)
+	}
+	return f, nil
+}
+
+// GetSize lets a Framebuffer stand in for the window when setting up
+// renderers.
+func (f *Framebuffer) GetSize() (int, int) {
+	return f.width, f.height
+}
+
+func (f *Framebuffer) Bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+	gl.Viewport(0, 0, int32(f.width), int32(f.height))
+}
+
+func (f *Framebuffer) Unbind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// ReadImage copies the colour buffer into an image, flipping it so the top
+// row comes first.
+func (f *Framebuffer) ReadImage() *image.RGBA {
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, f.fbo)
+	img := readPixels(f.width, f.height)
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	return img
+}
+
+// ReadScreen copies the window's back buffer, which holds the frame just
+// drawn until the buffers are swapped.
+func ReadScreen(width, height int) *image.RGBA {
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.ReadBuffer(gl.BACK)
+	return readPixels(width, height)
+}
+
+func readPixels(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	checkGLError("ReadPixels")
+
+	flipRows(img)
+	// The window's alpha channel isn't meaningful.
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// flipRows turns GL's bottom-up rows into image order.
+func flipRows(img *image.RGBA) {
+	stride := img.Stride
+	row := make([]byte, stride)
+	for top, bottom := 0, img.Rect.Dy()-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*stride : (top+1)*stride]
+		b := img.Pix[bottom*stride : (bottom+1)*stride]
+		copy(row, a)
+		copy(a, b)
+		copy(b, row)
+	}
+}
+
+func (f *Framebuffer) Delete() {
+	if f.fbo != 0 {
+		gl.DeleteFramebuffers(1, &f.fbo)
+	}
+	if f.color != 0 {
+		gl.DeleteRenderbuffers(1, &f.color)
+	}
+	if f.depth != 0 {
+		gl.DeleteRenderbuffers(1, &f.depth)
+	}
+	f.fbo, f.color, f.depth = 0, 0, 0
+}
@@ -10,26 +10,35 @@ import (
 )
 
 type GreedyMesher struct {
-	vao      uint32
-	vbo      uint32
-	ebo      uint32
+	meshBuffers
 	vertices []float32
 	indices  []uint32
+	size     int
 }
 
 const (
 	chunkSize = 10
+
+	greedyMesherSize = sectionSize
 )
 
 func NewGreedyMesher() *GreedyMesher {
-	return &GreedyMesher{}
+	return &GreedyMesher{size: greedyMesherSize}
 }
 
 func (m *GreedyMesher) CreateMesh(cubes []primitive.Cube) {
+	m.GenerateMesh(cubes)
+	m.setupBuffers()
+}
+
+func (m *GreedyMesher) GenerateMesh(cubes []primitive.Cube) {
 	m.vertices = nil
 	m.indices = nil
 	m.generateMesh(cubes)
-	m.setupBuffers()
+}
+
+func (m *GreedyMesher) VertexLayout() VertexLayout {
+	return PositionColorNormalLayout
 }
 
 func (m *GreedyMesher) Bind() {
@@ -55,8 +64,22 @@ func (m *GreedyMesher) String() string {
 	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
 }
 
+func (m *GreedyMesher) Clone() Mesher {
+	return &GreedyMesher{size: m.size}
+}
+
+func (m *GreedyMesher) CacheKey() string {
+	return fmt.Sprintf("greedy/1/size=%d", m.size)
+}
+
+func (m *GreedyMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.vertices = append(m.vertices[:0], vertices...)
+	m.indices = append(m.indices[:0], indices...)
+	m.setupBuffers()
+}
+
 func (m *GreedyMesher) populateSolidAndColors(cubes []primitive.Cube) ([][][]bool, map[[3]int]component.Color) {
-	const expandedChunkSize = 15
+	expandedChunkSize := m.size
 	solid := make([][][]bool, expandedChunkSize)
 	for i := range solid {
 		solid[i] = make([][]bool, expandedChunkSize)
@@ -77,7 +100,7 @@ func (m *GreedyMesher) populateSolidAndColors(cubes []primitive.Cube) ([][][]boo
 }
 
 func (m *GreedyMesher) generateMesh(cubes []primitive.Cube) {
-	const expandedChunkSize = 15
+	expandedChunkSize := m.size
 	solid, cubeColors := m.populateSolidAndColors(cubes)
 	for d := 0; d < 3; d++ {
 		m.generateDirectionMesh(d, solid, cubeColors, expandedChunkSize)
@@ -178,43 +201,49 @@ func (m *GreedyMesher) getColor(x [3]int, d, expandedChunkSize int, cubeColors m
 	return color
 }
 
+// The block behind a positive face is on the far side of the plane, so its
+// normal points along -d. Negative faces point along +d.
 func (m *GreedyMesher) generatePositiveFace(d int, x, du, dv [3]int, color component.Color) {
+	normal := [3]float32{}
+	normal[d] = -1
 	if d == 0 { // Positive X face
-		m.addFaceVertices(x, du, dv, color)
+		m.addFaceVertices(x, du, dv, color, normal)
 		m.addPositiveXFaceIndices()
 	} else if d == 1 { // Positive Y face
-		m.addFaceVertices(x, du, dv, color)
+		m.addFaceVertices(x, du, dv, color, normal)
 		m.addPositiveYFaceIndices()
 	} else if d == 2 { // Positive Z face
-		m.addFaceVertices(x, du, dv, color)
+		m.addFaceVertices(x, du, dv, color, normal)
 		m.addPositiveZFaceIndices()
 	}
 }
 
 func (m *GreedyMesher) generateNegativeFace(d int, x, du, dv [3]int, color component.Color) {
+	normal := [3]float32{}
+	normal[d] = 1
 	if d == 0 { // Negative X face
-		m.addFaceVertices(x, dv, du, color)
+		m.addFaceVertices(x, dv, du, color, normal)
 		m.addNegativeXFaceIndices()
 	} else if d == 1 { // Negative Y face
-		m.addFaceVertices(x, dv, du, color)
+		m.addFaceVertices(x, dv, du, color, normal)
 		m.addNegativeYFaceIndices()
 	} else if d == 2 { // Negative Z face
-		m.addFaceVertices(x, dv, du, color)
+		m.addFaceVertices(x, dv, du, color, normal)
 		m.addNegativeZFaceIndices()
 	}
 }
 
-func (m *GreedyMesher) addFaceVertices(x, du, dv [3]int, color component.Color) {
+func (m *GreedyMesher) addFaceVertices(x, du, dv [3]int, color component.Color, n [3]float32) {
 	m.vertices = append(m.vertices,
-		float32(x[0]), float32(x[1]), float32(x[2]), color[0], color[1], color[2],
-		float32(x[0]+du[0]), float32(x[1]+du[1]), float32(x[2]+du[2]), color[0], color[1], color[2],
-		float32(x[0]+dv[0]), float32(x[1]+dv[1]), float32(x[2]+dv[2]), color[0], color[1], color[2],
-		float32(x[0]+du[0]+dv[0]), float32(x[1]+du[1]+dv[1]), float32(x[2]+du[2]+dv[2]), color[0], color[1], color[2],
+		float32(x[0]), float32(x[1]), float32(x[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+du[0]), float32(x[1]+du[1]), float32(x[2]+du[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+dv[0]), float32(x[1]+dv[1]), float32(x[2]+dv[2]), color[0], color[1], color[2], n[0], n[1], n[2],
+		float32(x[0]+du[0]+dv[0]), float32(x[1]+du[1]+dv[1]), float32(x[2]+du[2]+dv[2]), color[0], color[1], color[2], n[0], n[1], n[2],
 	)
 }
 
 func (m *GreedyMesher) addPositiveXFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx+2, idx+1, idx, // First triangle
 		idx+2, idx+3, idx+1, // Second triangle
@@ -222,7 +251,7 @@ func (m *GreedyMesher) addPositiveXFaceIndices() {
 }
 
 func (m *GreedyMesher) addPositiveYFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx, idx+2, idx+1, // First triangle
 		idx+1, idx+2, idx+3, // Second triangle
@@ -230,7 +259,7 @@ func (m *GreedyMesher) addPositiveYFaceIndices() {
 }
 
 func (m *GreedyMesher) addPositiveZFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx+2, idx+1, idx, // First triangle
 		idx+2, idx+3, idx+1, // Second triangle
@@ -238,7 +267,7 @@ func (m *GreedyMesher) addPositiveZFaceIndices() {
 }
 
 func (m *GreedyMesher) addNegativeXFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx, idx+2, idx+1, // First triangle
 		idx+1, idx+2, idx+3, // Second triangle
@@ -246,7 +275,7 @@ func (m *GreedyMesher) addNegativeXFaceIndices() {
 }
 
 func (m *GreedyMesher) addNegativeYFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx+2, idx+1, idx, // First triangle
 		idx+2, idx+3, idx+1, // Second triangle
@@ -254,7 +283,7 @@ func (m *GreedyMesher) addNegativeYFaceIndices() {
 }
 
 func (m *GreedyMesher) addNegativeZFaceIndices() {
-	idx := uint32(len(m.vertices)/6 - 4)
+	idx := uint32(len(m.vertices)/9 - 4)
 	m.indices = append(m.indices,
 		idx, idx+2, idx+1, // First triangle
 		idx+1, idx+2, idx+3, // Second triangle
@@ -270,28 +299,7 @@ func (m *GreedyMesher) markAsVisited(visited []bool, expandedChunkSize, n, w, h
 }
 
 func (m *GreedyMesher) setupBuffers() {
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(m.vertices)*4, gl.Ptr(m.vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(m.indices)*4, gl.Ptr(m.indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 6*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, 6*4, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	m.vao = vao
-	m.vbo = vbo
-	m.ebo = ebo
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.STATIC_DRAW)
 }
 
 func (m *GreedyMesher) EnableBackfaceCulling() {
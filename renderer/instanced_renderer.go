@@ -0,0 +1,278 @@
+package renderer
+
+import (
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// Instance is one cube drawn by InstancedBlockRenderer.
+type Instance struct {
+	Position mgl32.Vec3
+	Rotation mgl32.Quat
+	Scale    float32
+	Color    component.Color
+	// Layer is the texture array layer, or -1 for colour only.
+	Layer int32
+}
+
+// instanceLayout is a model matrix, colour and layer per instance.
+var instanceLayout = VertexLayout{
+	Stride: 20 * 4,
+	Attributes: []VertexAttribute{
+		{Name: "model0", Location: 3, Size: 4, Type: gl.FLOAT, Offset: 0, Divisor: 1},
+		{Name: "model1", Location: 4, Size: 4, Type: gl.FLOAT, Offset: 4 * 4, Divisor: 1},
+		{Name: "model2", Location: 5, Size: 4, Type: gl.FLOAT, Offset: 8 * 4, Divisor: 1},
+		{Name: "model3", Location: 6, Size: 4, Type: gl.FLOAT, Offset: 12 * 4, Divisor: 1},
+		{Name: "color", Location: 7, Size: 3, Type: gl.FLOAT, Offset: 16 * 4, Divisor: 1},
+		{Name: "layer", Location: 8, Size: 1, Type: gl.FLOAT, Offset: 19 * 4, Divisor: 1},
+	},
+}
+
+// InstancedBlockRenderer draws one unit cube mesh many times. Instance data
+// is rebuilt and streamed to the GPU every frame, so instances can move
+// freely without remeshing.
+type InstancedBlockRenderer struct {
+	program      uint32
+	camera       Camera
+	window       Window
+	bus          message.MessageBus
+	events       chan string
+	wireframe    bool
+	textureArray uint32
+
+	cube        meshBuffers
+	indexCount  int32
+	vertexCount int
+	instanceVBO uint32
+	data        []float32
+	stats       CullStats
+
+	instances  []Instance
+	update     func(dt float32, instances []Instance) []Instance
+	lastUpdate time.Time
+
+	sceneState
+}
+
+// NewInstancedBlockRenderer samples textureArray for instances with a layer.
+// Pass 0 to draw colours only.
+func NewInstancedBlockRenderer(textureArray uint32) *InstancedBlockRenderer {
+	program, err := shader.NewProgramFromFiles("instanced_vertex_shader.glsl", "instanced_fragment_shader.glsl")
+	if err != nil {
+		logrus.Fatalln("failed to create shader program:", err)
+	}
+
+	r := &InstancedBlockRenderer{
+		program:      program,
+		events:       make(chan string),
+		textureArray: textureArray,
+		sceneState:   newSceneState(),
+	}
+	r.setupBuffers()
+	return r
+}
+
+func (r *InstancedBlockRenderer) setupBuffers() {
+	unit := primitive.Cube{Size: 1}
+	indices := unit.Indices(0)
+	r.cube.uploadIndexed(unit.Vertices(), indices, blockLayout, gl.STATIC_DRAW)
+	r.indexCount = int32(len(indices))
+	r.vertexCount = len(unit.Vertices()) / blockLayout.Floats()
+
+	gl.BindVertexArray(r.cube.vao)
+	gl.GenBuffers(1, &r.instanceVBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instanceVBO)
+	instanceLayout.Enable()
+	gl.BindVertexArray(0)
+}
+
+func (r *InstancedBlockRenderer) SetCamera(camera Camera) {
+	r.camera = camera
+}
+
+func (r *InstancedBlockRenderer) SetWindow(window Window) {
+	r.window = window
+}
+
+func (r *InstancedBlockRenderer) SetMessageBus(m message.MessageBus) {
+	r.bus = m
+	go r.subscribeToEvents()
+}
+
+// SetInstances replaces every instance. Call it from the render thread, or
+// use OnUpdate.
+func (r *InstancedBlockRenderer) SetInstances(instances []Instance) {
+	r.instances = instances
+}
+
+func (r *InstancedBlockRenderer) AddCube(cube primitive.Cube) {
+	r.instances = append(r.instances, Instance{
+		Position: mgl32.Vec3{cube.X, cube.Y, cube.Z},
+		Rotation: mgl32.QuatIdent(),
+		Scale:    cube.Size,
+		Color:    cube.Color,
+		Layer:    instanceLayer(cube),
+	})
+}
+
+// instanceLayer is the layer an instance of cube samples: its front face's,
+// or -1 for a cube without layers so it keeps its colour.
+func instanceLayer(cube primitive.Cube) int32 {
+	if cube.Layers == (primitive.CubeLayers{}) {
+		return -1
+	}
+	return int32(cube.Layers.Front)
+}
+
+// OnUpdate sets a function called at the start of each frame with the time
+// since the last frame in seconds. It returns the instances to draw.
+func (r *InstancedBlockRenderer) OnUpdate(update func(dt float32, instances []Instance) []Instance) {
+	r.update = update
+}
+
+func (r *InstancedBlockRenderer) ToggleWireframe() {
+	r.wireframe = !r.wireframe
+	if r.wireframe {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+	checkGLError("ToggleWireframe")
+}
+
+func (r *InstancedBlockRenderer) Render() {
+	now := time.Now()
+	if r.update != nil {
+		dt := float32(0)
+		if !r.lastUpdate.IsZero() {
+			dt = float32(now.Sub(r.lastUpdate).Seconds())
+		}
+		r.instances = r.update(dt, r.instances)
+	}
+	r.lastUpdate = now
+
+	gl.Enable(gl.DEPTH_TEST)
+	gl.UseProgram(r.program)
+	r.SetShaderUniforms()
+
+	visible := r.uploadInstances()
+	if visible > 0 {
+		gl.Enable(gl.CULL_FACE)
+		gl.CullFace(gl.BACK)
+		gl.FrontFace(gl.CCW)
+		if r.textureArray != 0 {
+			gl.ActiveTexture(gl.TEXTURE0)
+			gl.BindTexture(gl.TEXTURE_2D_ARRAY, r.textureArray)
+		}
+		gl.BindVertexArray(r.cube.vao)
+		gl.DrawElementsInstanced(gl.TRIANGLES, r.indexCount, gl.UNSIGNED_INT, nil, int32(visible))
+		gl.BindVertexArray(0)
+		checkGLError("DrawInstances")
+	}
+
+	r.drainEvents()
+}
+
+// uploadInstances streams the instances inside the frustum and returns how
+// many there are. The buffer is orphaned before filling it so the driver
+// doesn't stall on the previous frame's draw.
+func (r *InstancedBlockRenderer) uploadInstances() int {
+	frustum := viewFrustum(r.camera, r.window)
+	r.data = r.data[:0]
+	visible := 0
+	for _, in := range r.instances {
+		// A rotated cube stays inside the sphere through its corners.
+		if !frustum.IntersectsSphere(in.Position, in.Scale*0.8661) {
+			continue
+		}
+		visible++
+		model := mgl32.Translate3D(in.Position[0], in.Position[1], in.Position[2]).
+			Mul4(in.Rotation.Mat4()).
+			Mul4(mgl32.Scale3D(in.Scale, in.Scale, in.Scale))
+		r.data = append(r.data, model[:]...)
+		r.data = append(r.data, in.Color[0], in.Color[1], in.Color[2], float32(in.Layer))
+	}
+
+	r.stats = CullStats{Visible: visible, Culled: len(r.instances) - visible}
+	if visible == 0 {
+		return 0
+	}
+
+	size := len(r.data) * 4
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instanceVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, size, nil, gl.STREAM_DRAW)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(r.data))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return visible
+}
+
+// CullStats reports how many instances the last frame drew and skipped.
+func (r *InstancedBlockRenderer) CullStats() CullStats {
+	return r.stats
+}
+
+// Metrics counts the instanced cubes drawn last frame.
+func (r *InstancedBlockRenderer) Metrics() Metrics {
+	return Metrics{
+		Mesher:    "instanced",
+		Vertices:  r.stats.Visible * r.vertexCount,
+		Triangles: r.stats.Visible * int(r.indexCount) / 3,
+		Cull:      r.stats,
+	}
+}
+
+func (r *InstancedBlockRenderer) SetShaderUniforms() {
+	view := r.camera.GetViewMatrix()
+	projection := perspective(r.camera, r.window)
+
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("view\x00")), 1, false, &view[0])
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("projection\x00")), 1, false, &projection[0])
+	r.setSceneUniforms(r.program, nil, r.camera.GetPosition())
+
+	textured := int32(0)
+	if r.textureArray != 0 {
+		textured = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.program, gl.Str("textured\x00")), textured)
+	gl.Uniform1i(gl.GetUniformLocation(r.program, gl.Str("textureArray\x00")), 0)
+	checkGLError("SetShaderUniforms")
+}
+
+func (r *InstancedBlockRenderer) drainEvents() {
+	for {
+		select {
+		case event := <-r.events:
+			if event == "ToggleWireframe" {
+				r.ToggleWireframe()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (r *InstancedBlockRenderer) subscribeToEvents() {
+	if r.bus == nil {
+		logrus.Println("MessageBus not set for InstancedBlockRenderer")
+		return
+	}
+
+	msg := r.bus.Subscribe()
+	defer r.bus.Unsubscribe(msg)
+
+	for m := range msg {
+		if r.handleSceneMessage(m) {
+			continue
+		}
+		if m.GetTopic() == "ToggleWireframe" {
+			r.events <- m.GetTopic()
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+func TestInstancedAddCubeLayers(t *testing.T) {
+	colored := primitive.Cube{Size: 1, Color: component.Color{1, 0, 0}}
+	textured := primitive.Cube{Size: 1, Layers: primitive.CubeLayers{Front: 3, Top: 1}}
+
+	r := &InstancedBlockRenderer{}
+	r.AddCube(colored)
+	r.AddCube(textured)
+	if got := r.instances[0].Layer; got != -1 {
+		t.Errorf("a cube without layers got layer %d, want -1 to keep its colour", got)
+	}
+	if got := r.instances[1].Layer; got != 3 {
+		t.Errorf("a textured cube got layer %d, want its front layer 3", got)
+	}
+}
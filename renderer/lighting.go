@@ -0,0 +1,229 @@
+package renderer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxPointLights matches MAX_POINT_LIGHTS in shader/lighting.glsl. Extra
+// lights are ignored.
+const MaxPointLights = 8
+
+// PointLight lights surfaces facing it, fading to nothing at Radius.
+type PointLight struct {
+	Position mgl32.Vec3
+	Color    component.Color
+	Radius   float32
+}
+
+// Lighting is the light shared by the lit shaders: a sun, an ambient term
+// and up to MaxPointLights point lights.
+//
+// It can be changed over the message bus with these topics:
+//
+//	SetLighting      Lighting
+//	SetSunDirection  mgl32.Vec3
+//	SetSunColor      component.Color
+//	SetAmbient       float32
+//	SetPointLights   []PointLight
+type Lighting struct {
+	SunDirection mgl32.Vec3
+	SunColor     component.Color
+	Ambient      float32
+	PointLights  []PointLight
+}
+
+func DefaultLighting() Lighting {
+	return Lighting{
+		SunDirection: mgl32.Vec3{-0.4, -1, -0.6},
+		SunColor:     component.Color{1, 1, 1},
+		Ambient:      0.35,
+	}
+}
+
+// setUniforms uploads the lighting to a program that includes
+// lighting.glsl. The program must be in use.
+func (l Lighting) setUniforms(program uint32) {
+	sunColor := mgl32.Vec3(l.SunColor)
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("lightDirection\x00")), 1, &l.SunDirection[0])
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("sunColor\x00")), 1, &sunColor[0])
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("ambient\x00")), l.Ambient)
+
+	// Shadows stay off until a shadow pass binds its map. The sampler is
+	// always pointed at its own unit so it never shares one with a texture
+	// of another type.
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("shadowsEnabled\x00")), 0)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("shadowMap\x00")), shadowTextureUnit)
+
+	count := len(l.PointLights)
+	if count > MaxPointLights {
+		count = MaxPointLights
+	}
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("pointLightCount\x00")), int32(count))
+	if count == 0 {
+		return
+	}
+
+	positions := make([]float32, 0, count*3)
+	colors := make([]float32, 0, count*3)
+	radii := make([]float32, 0, count)
+	for _, light := range l.PointLights[:count] {
+		positions = append(positions, light.Position[:]...)
+		colors = append(colors, light.Color[:]...)
+		radii = append(radii, light.Radius)
+	}
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("pointLightPositions\x00")), int32(count), &positions[0])
+	gl.Uniform3fv(gl.GetUniformLocation(program, gl.Str("pointLightColors\x00")), int32(count), &colors[0])
+	gl.Uniform1fv(gl.GetUniformLocation(program, gl.Str("pointLightRadii\x00")), int32(count), &radii[0])
+}
+
+// Light mirrors lighting() in lighting.glsl for the software renderer. It
+// returns the light reaching a surface at position facing normal.
+func (l Lighting) Light(normal, position mgl32.Vec3) mgl32.Vec3 {
+	light := mgl32.Vec3{l.Ambient, l.Ambient, l.Ambient}
+	if normal.Len() == 0 {
+		return light
+	}
+	n := normal.Normalize()
+
+	diffuse := float32(math.Max(float64(n.Dot(l.SunDirection.Mul(-1).Normalize())), 0))
+	light = light.Add(mgl32.Vec3(l.SunColor).Mul((1 - l.Ambient) * diffuse))
+
+	for i, p := range l.PointLights {
+		if i == MaxPointLights {
+			break
+		}
+		toLight := p.Position.Sub(position)
+		distance := toLight.Len()
+		if distance >= p.Radius || distance == 0 {
+			continue
+		}
+		falloff := 1 - distance/p.Radius
+		facing := float32(math.Max(float64(n.Dot(toLight.Mul(1/distance))), 0))
+		light = light.Add(mgl32.Vec3(p.Color).Mul(facing * falloff * falloff))
+	}
+	return light
+}
+
+// lightingState is embedded in sceneState. Bus updates arrive on another
+// goroutine, so access is locked.
+type lightingState struct {
+	mu       sync.Mutex
+	lighting Lighting
+}
+
+func (s *lightingState) SetLighting(l Lighting) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l.PointLights = append([]PointLight(nil), l.PointLights...)
+	s.lighting = l
+}
+
+// Lighting returns a copy of the current lighting.
+func (s *lightingState) Lighting() Lighting {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.lighting
+	l.PointLights = append([]PointLight(nil), l.PointLights...)
+	return l
+}
+
+func (s *lightingState) SetLightDirection(direction mgl32.Vec3) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lighting.SunDirection = direction
+}
+
+func (s *lightingState) SetSunColor(color component.Color) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lighting.SunColor = color
+}
+
+func (s *lightingState) SetAmbient(ambient float32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lighting.Ambient = ambient
+}
+
+func (s *lightingState) SetPointLights(lights []PointLight) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lighting.PointLights = append([]PointLight(nil), lights...)
+}
+
+// handleLightingMessage applies a lighting topic and reports whether m was
+// one.
+func (s *lightingState) handleLightingMessage(m message.Request) bool {
+	var ok bool
+	switch m.GetTopic() {
+	case "SetLighting":
+		var l Lighting
+		if l, ok = m.GetPayload().(Lighting); ok {
+			s.SetLighting(l)
+		}
+	case "SetSunDirection":
+		var d mgl32.Vec3
+		if d, ok = m.GetPayload().(mgl32.Vec3); ok {
+			s.SetLightDirection(d)
+		}
+	case "SetSunColor":
+		var c component.Color
+		if c, ok = m.GetPayload().(component.Color); ok {
+			s.SetSunColor(c)
+		}
+	case "SetAmbient":
+		var a float32
+		if a, ok = m.GetPayload().(float32); ok {
+			s.SetAmbient(a)
+		}
+	case "SetPointLights":
+		var lights []PointLight
+		if lights, ok = m.GetPayload().([]PointLight); ok {
+			s.SetPointLights(lights)
+		}
+	default:
+		return false
+	}
+	if !ok {
+		logrus.Warnf("ignoring %s with payload %T", m.GetTopic(), m.GetPayload())
+	}
+	return true
+}
+
+// sceneState is embedded by renderers that light their scene. It holds the
+// lighting and fog, applies their bus topics and uploads them, so every lit
+// program gets the same uniforms.
+type sceneState struct {
+	lightingState
+	fogState
+}
+
+func newSceneState() sceneState {
+	return sceneState{
+		lightingState: lightingState{lighting: DefaultLighting()},
+		fogState:      fogState{fog: DefaultFog()},
+	}
+}
+
+// handleSceneMessage applies a lighting or fog topic and reports whether m
+// was one.
+func (s *sceneState) handleSceneMessage(m message.Request) bool {
+	return s.handleLightingMessage(m) || s.handleFogMessage(m)
+}
+
+// setSceneUniforms uploads the lighting, the shadow map when shadows isn't
+// nil and the fog seen from eye. The program must be in use.
+func (s *sceneState) setSceneUniforms(program uint32, shadows *shadowPass, eye mgl32.Vec3) {
+	s.Lighting().setUniforms(program)
+	if shadows != nil {
+		shadows.bind(program)
+	}
+	s.Fog().setUniforms(program, eye)
+}
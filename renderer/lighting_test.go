@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func near(a, b mgl32.Vec3) bool {
+	return a.ApproxEqualThreshold(b, 1e-4)
+}
+
+func TestLightingSun(t *testing.T) {
+	l := Lighting{SunDirection: mgl32.Vec3{0, -1, 0}, SunColor: component.Color{1, 1, 1}, Ambient: 0.25}
+
+	if got := l.Light(mgl32.Vec3{0, 1, 0}, mgl32.Vec3{}); !near(got, mgl32.Vec3{1, 1, 1}) {
+		t.Errorf("surface facing the sun = %v, want full light", got)
+	}
+	if got := l.Light(mgl32.Vec3{0, -1, 0}, mgl32.Vec3{}); !near(got, mgl32.Vec3{0.25, 0.25, 0.25}) {
+		t.Errorf("surface facing away = %v, want ambient", got)
+	}
+	if got := l.Light(mgl32.Vec3{}, mgl32.Vec3{}); !near(got, mgl32.Vec3{0.25, 0.25, 0.25}) {
+		t.Errorf("surface without a normal = %v, want ambient", got)
+	}
+}
+
+func TestLightingPointLightFalloff(t *testing.T) {
+	l := Lighting{
+		SunDirection: mgl32.Vec3{0, -1, 0},
+		PointLights: []PointLight{
+			{Position: mgl32.Vec3{0, 4, 0}, Color: component.Color{1, 0, 0}, Radius: 8},
+		},
+	}
+	up := mgl32.Vec3{0, 1, 0}
+
+	// Halfway to the radius the falloff is 0.5², straight below the light.
+	if got := l.Light(up, mgl32.Vec3{}); !near(got, mgl32.Vec3{0.25, 0, 0}) {
+		t.Errorf("light at half radius = %v, want 0.25 red", got)
+	}
+	if got := l.Light(up, mgl32.Vec3{0, -5, 0}); !near(got, mgl32.Vec3{}) {
+		t.Errorf("light past its radius = %v, want none", got)
+	}
+	if got := l.Light(mgl32.Vec3{0, -1, 0}, mgl32.Vec3{}); !near(got, mgl32.Vec3{}) {
+		t.Errorf("surface facing away from the light = %v, want none", got)
+	}
+}
+
+func TestLightingIgnoresExtraPointLights(t *testing.T) {
+	var l Lighting
+	for i := 0; i < MaxPointLights+4; i++ {
+		l.PointLights = append(l.PointLights, PointLight{Position: mgl32.Vec3{0, 1, 0}, Color: component.Color{0.1, 0, 0}, Radius: 2})
+	}
+	got := l.Light(mgl32.Vec3{0, 1, 0}, mgl32.Vec3{})
+	want := float32(MaxPointLights) * 0.1 * 0.25
+	if got[0] < want-1e-4 || got[0] > want+1e-4 {
+		t.Errorf("red = %v, want %v from %d lights", got[0], want, MaxPointLights)
+	}
+}
+
+func TestHandleLightingMessage(t *testing.T) {
+	s := lightingState{lighting: DefaultLighting()}
+	messages := []message.Message{
+		{Topic: "SetSunDirection", Payload: mgl32.Vec3{1, 0, 0}},
+		{Topic: "SetAmbient", Payload: float32(0.5)},
+		{Topic: "SetPointLights", Payload: []PointLight{{Radius: 3}}},
+		{Topic: "SetAmbient", Payload: 0.9}, // wrong type, ignored
+	}
+	for _, m := range messages {
+		if !s.handleLightingMessage(m) {
+			t.Errorf("%s not handled", m.Topic)
+		}
+	}
+	if s.handleLightingMessage(message.Message{Topic: "ToggleWireframe"}) {
+		t.Error("ToggleWireframe handled as lighting")
+	}
+
+	l := s.Lighting()
+	if l.SunDirection != (mgl32.Vec3{1, 0, 0}) || l.Ambient != 0.5 || len(l.PointLights) != 1 {
+		t.Errorf("lighting = %+v", l)
+	}
+}
@@ -0,0 +1,203 @@
+package renderer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+// CacheableMesher is a mesher whose output a MeshCache can store.
+//
+// CacheKey names the mesher's algorithm version and settings; change it
+// whenever the mesher would produce different output for the same cubes.
+// CreateMeshFromData uploads a mesh previously returned by GetMesh.
+type CacheableMesher interface {
+	Mesher
+	CacheKey() string
+	CreateMeshFromData(vertices []float32, indices []uint32)
+}
+
+const (
+	meshCacheMagic   = "CUBEMESH"
+	meshCacheVersion = 1
+)
+
+// MeshCache stores generated meshes in a directory, one file per key.
+type MeshCache struct {
+	dir string
+}
+
+func NewMeshCache(dir string) (*MeshCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mesh cache: %w", err)
+	}
+	return &MeshCache{dir: dir}, nil
+}
+
+// Key hashes the mesher's cache key, the cubes being meshed and the cubes
+// bordering them.
+func (c *MeshCache) Key(mesher CacheableMesher, cubes, border []primitive.Cube) string {
+	return cacheKey(meshCacheVersion, mesher, cubes, border)
+}
+
+func cacheKey(version int, mesher CacheableMesher, cubes, border []primitive.Cube) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00%d\x00", version, mesher.CacheKey(), len(cubes))
+	for _, cube := range cubes {
+		hashCube(h, cube)
+	}
+	fmt.Fprintf(h, "%d\x00", len(border))
+	for _, cube := range border {
+		hashCube(h, cube)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashCube writes everything about a cube that can change a mesh. Texture
+// handles are left out since they differ between runs.
+func hashCube(w io.Writer, cube primitive.Cube) {
+	var model string
+	if cube.Model != nil {
+		model = cube.Model.Name
+	}
+	binary.Write(w, binary.LittleEndian, struct {
+		Position    [3]float32
+		Color       [3]float32
+		Size        float32
+		Layers      primitive.CubeLayers
+		Translucent bool
+		Opacity     float32
+		Hide        [7]bool
+	}{
+		Position:    [3]float32{cube.X, cube.Y, cube.Z},
+		Color:       [3]float32(cube.Color),
+		Size:        cube.Size,
+		Layers:      cube.Layers,
+		Translucent: cube.Translucent,
+		Opacity:     cube.Opacity,
+		Hide:        [7]bool{cube.ShouldHide, cube.HideFront, cube.HideBack, cube.HideLeft, cube.HideRight, cube.HideTop, cube.HideBottom},
+	})
+	fmt.Fprintf(w, "%s\x00", model)
+}
+
+func (c *MeshCache) path(key string) string {
+	return filepath.Join(c.dir, key+".mesh")
+}
+
+// Load returns the mesh stored under key. A missing, unreadable or damaged
+// entry is a miss.
+func (c *MeshCache) Load(key string) ([]float32, []uint32, bool) {
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		return nil, nil, false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, false
+	}
+
+	vertices, indices, err := readMesh(bufio.NewReader(f), info.Size())
+	if err != nil {
+		return nil, nil, false
+	}
+	return vertices, indices, true
+}
+
+// Store writes a mesh under key. The file is renamed into place so readers
+// never see a partial entry.
+func (c *MeshCache) Store(key string, vertices []float32, indices []uint32) error {
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := writeMesh(w, vertices, indices); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+type meshHeader struct {
+	Magic    [8]byte
+	Version  uint32
+	Vertices uint32
+	Indices  uint32
+}
+
+// meshHeaderSize is the encoded size of meshHeader.
+const meshHeaderSize = 8 + 3*4
+
+func writeMesh(w io.Writer, vertices []float32, indices []uint32) error {
+	header := meshHeader{
+		Version:  meshCacheVersion,
+		Vertices: uint32(len(vertices)),
+		Indices:  uint32(len(indices)),
+	}
+	copy(header.Magic[:], meshCacheMagic)
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4*(len(vertices)+len(indices)))
+	for i, v := range vertices {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	for i, idx := range indices {
+		binary.LittleEndian.PutUint32(buf[(len(vertices)+i)*4:], idx)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// readMesh reads an entry of size bytes. The counts in the header must
+// account for exactly the rest of the entry, so a damaged header can't ask
+// for more memory than the file holds.
+func readMesh(r io.Reader, size int64) ([]float32, []uint32, error) {
+	var header meshHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, nil, err
+	}
+	if string(header.Magic[:]) != meshCacheMagic || header.Version != meshCacheVersion {
+		return nil, nil, errors.New("not a mesh cache entry")
+	}
+	if want := meshHeaderSize + 4*(int64(header.Vertices)+int64(header.Indices)); want != size {
+		return nil, nil, fmt.Errorf("mesh cache entry is %d bytes, header describes %d", size, want)
+	}
+
+	buf := make([]byte, 4*(int(header.Vertices)+int(header.Indices)))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, nil, err
+	}
+	vertices := make([]float32, header.Vertices)
+	for i := range vertices {
+		vertices[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	var indices []uint32
+	if header.Indices > 0 {
+		indices = make([]uint32, header.Indices)
+		for i := range indices {
+			indices[i] = binary.LittleEndian.Uint32(buf[(len(vertices)+i)*4:])
+		}
+	}
+	return vertices, indices, nil
+}
@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+)
+
+// keyedMesher is a greedy mesher with a different cache key.
+type keyedMesher struct {
+	*GreedyMesher
+	key string
+}
+
+func (m keyedMesher) CacheKey() string {
+	return m.key
+}
+
+func TestMeshCacheRoundTrip(t *testing.T) {
+	cache, err := NewMeshCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewGreedyMesher()
+	cubes := scene.Terrain(8, 1)
+	m.GenerateMesh(cubes)
+	vertices, indices := m.GetMesh()
+
+	key := cache.Key(m, cubes, nil)
+	if _, _, ok := cache.Load(key); ok {
+		t.Fatal("empty cache hit")
+	}
+	if err := cache.Store(key, vertices, indices); err != nil {
+		t.Fatal(err)
+	}
+	gotVertices, gotIndices, ok := cache.Load(key)
+	if !ok {
+		t.Fatal("stored mesh missed")
+	}
+	if !reflect.DeepEqual(gotVertices, vertices) || !reflect.DeepEqual(gotIndices, indices) {
+		t.Error("loaded mesh differs from the stored one")
+	}
+}
+
+func TestMeshCacheKey(t *testing.T) {
+	cache := &MeshCache{}
+	m := NewGreedyMesher()
+	cubes := scene.Solid(2)
+	key := cache.Key(m, cubes, nil)
+
+	if cache.Key(m, cubes, nil) != key {
+		t.Error("key isn't stable")
+	}
+	if cache.Key(keyedMesher{m, "greedy/2"}, cubes, nil) == key {
+		t.Error("key ignores the mesher's CacheKey")
+	}
+	if cacheKey(meshCacheVersion+1, m, cubes, nil) == key {
+		t.Error("key ignores the cache version")
+	}
+	if cache.Key(m, cubes[1:], nil) == key {
+		t.Error("key ignores the cubes")
+	}
+	if cache.Key(m, cubes, []primitive.Cube{{Size: 1}}) == key {
+		t.Error("key ignores the border")
+	}
+}
+
+func TestMeshCacheDamagedEntriesMiss(t *testing.T) {
+	cache, err := NewMeshCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store("mesh", []float32{1, 2, 3, 4, 5, 6}, []uint32{0, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	good, err := os.ReadFile(cache.path("mesh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	huge := append([]byte(nil), good...)
+	binary.LittleEndian.PutUint32(huge[12:], 1<<30)
+	wrongVersion := append([]byte(nil), good...)
+	binary.LittleEndian.PutUint32(wrongVersion[8:], meshCacheVersion+1)
+
+	for name, data := range map[string][]byte{
+		"truncated":     good[:len(good)-2],
+		"header only":   good[:meshHeaderSize],
+		"short header":  good[:6],
+		"trailing data": append(append([]byte(nil), good...), 0, 0, 0, 0),
+		"huge count":    huge,
+		"wrong version": wrongVersion,
+		"bad magic":     append([]byte("NOTAMESH"), good[8:]...),
+	} {
+		if err := os.WriteFile(cache.path("mesh"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := cache.Load("mesh"); ok {
+			t.Errorf("%s entry was a hit", name)
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package renderer
 
 import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dfirebaugh/cube/pkg/component"
 	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/occlusion"
 	"github.com/dfirebaugh/cube/pkg/primitive"
 	"github.com/dfirebaugh/cube/shader"
 	"github.com/go-gl/gl/v3.3-core/gl"
@@ -9,38 +15,66 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// MeshRenderer splits its cubes into sections of sectionSize³ cells. Each
+// section has its own meshes, and only sections touched by an edit are
+// rebuilt.
 type MeshRenderer struct {
-	program   uint32
-	cubes     []primitive.Cube
-	wireframe bool
-	camera    Camera
-	window    Window
-	bus       message.MessageBus
-	events    chan string
-	mesher    Mesher
-	meshDirty bool
+	program      uint32
+	colorProgram uint32
+	wireframe    bool
+	camera       Camera
+	window       Window
+	bus          message.MessageBus
+	events       chan string
+	mesher       Mesher
+	sceneState
+
+	sections map[sectionKey]*meshSection
+	dirty    []*meshSection
+	cache    *MeshCache
+
+	// visible holds the sections inside the frustum this frame.
+	visible   []*meshSection
+	stats     CullStats
+	occlusion bool
+	shadows   shadowPass
 }
 
+// translucentSortDistance is how far the camera moves before translucent
+// quads are sorted again.
+const translucentSortDistance = 1.0
+
+// occlusionDistance is how many sections the visibility search reaches out,
+// enough to cover a far plane at distance.
+func occlusionDistance(distance float32) int {
+	return int(distance/sectionSize) + 1
+}
+
+// NewMeshRenderer builds each section with a clone of mesher.
 func NewMeshRenderer(mesher Mesher) *MeshRenderer {
 	renderer := &MeshRenderer{
-		events:    make(chan string),
-		mesher:    mesher,
-		meshDirty: true,
-	}
-	vertexShaderSource, err := shader.ShaderFS.ReadFile("cube_vertex_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read vertex shader: %v", err)
+		events:     make(chan string),
+		mesher:     mesher,
+		sceneState: newSceneState(),
+		sections:   make(map[sectionKey]*meshSection),
+		occlusion:  true,
 	}
 
-	fragmentShaderSource, err := shader.ShaderFS.ReadFile("cube_fragment_shader.glsl")
-	if err != nil {
-		logrus.Fatalf("failed to read fragment shader: %v", err)
-	}
-	program, err := shader.NewProgram(string(vertexShaderSource)+"\x00", string(fragmentShaderSource)+"\x00")
+	// Block models and translucent cubes always use the coloured cube shaders.
+	colorProgram, err := shader.NewProgramFromFiles("cube_vertex_shader.glsl", "cube_fragment_shader.glsl")
 	if err != nil {
 		logrus.Fatalln("failed to create shader program:", err)
 	}
-	renderer.program = program
+	renderer.colorProgram = colorProgram
+	renderer.program = colorProgram
+
+	if s, ok := mesher.(ShaderMesher); ok {
+		program, err := shader.NewProgramFromFiles(s.Shaders())
+		if err != nil {
+			logrus.Fatalln("failed to create shader program:", err)
+		}
+		renderer.program = program
+	}
 
 	return renderer
 }
@@ -59,8 +93,97 @@ func (r *MeshRenderer) SetMessageBus(m message.MessageBus) {
 }
 
 func (r *MeshRenderer) AddCube(cube primitive.Cube) {
-	r.cubes = append(r.cubes, cube)
-	r.meshDirty = true
+	key := sectionOf(cube.X, cube.Y, cube.Z)
+	section, ok := r.sections[key]
+	if !ok {
+		section = newMeshSection(key, r.mesher.Clone())
+		r.sections[key] = section
+	}
+	section.add(cube)
+	r.markDirty(section)
+	r.markBorderDirty(key, int(math.Floor(float64(cube.X))), int(math.Floor(float64(cube.Y))), int(math.Floor(float64(cube.Z))))
+}
+
+// RemoveCube removes the cubes in the cell at x, y, z. Sections left empty
+// free their GL buffers.
+func (r *MeshRenderer) RemoveCube(x, y, z int) {
+	key := sectionOf(float32(x), float32(y), float32(z))
+	section, ok := r.sections[key]
+	if !ok || !section.removeAt(x, y, z) {
+		return
+	}
+	r.markBorderDirty(key, x, y, z)
+	if len(section.cubes) == 0 {
+		section.delete()
+		delete(r.sections, key)
+		section.dirty = false
+		return
+	}
+	r.markDirty(section)
+}
+
+// BlockAt returns the cube in the cell at x, y, z, which makes the renderer
+// a primitive.BlockSource for raycasts.
+func (r *MeshRenderer) BlockAt(x, y, z int) (primitive.Cube, bool) {
+	section, ok := r.sections[sectionOf(float32(x), float32(y), float32(z))]
+	if !ok {
+		return primitive.Cube{}, false
+	}
+	return section.blockAt(x, y, z)
+}
+
+// SetMeshCache makes sections load unchanged meshes from cache instead of
+// regenerating them. Only meshers that implement CacheableMesher are cached.
+func (r *MeshRenderer) SetMeshCache(cache *MeshCache) {
+	r.cache = cache
+}
+
+// markBorderDirty remeshes the neighbouring sections that the cell at x, y,
+// z touches, including those across edges and corners, since it is part of
+// their border.
+func (r *MeshRenderer) markBorderDirty(key sectionKey, x, y, z int) {
+	for _, neighbor := range borderNeighbors(key, x, y, z) {
+		if section, ok := r.sections[neighbor]; ok {
+			r.markDirty(section)
+		}
+	}
+}
+
+// borderNeighbors returns the keys of the sections around key whose border
+// holds the cell at x, y, z.
+func borderNeighbors(key sectionKey, x, y, z int) []sectionKey {
+	origin := key.origin()
+	cell := [3]int{x - int(origin[0]), y - int(origin[1]), z - int(origin[2])}
+	var steps [3][]int
+	for d := 0; d < 3; d++ {
+		steps[d] = []int{0}
+		switch cell[d] {
+		case 0:
+			steps[d] = append(steps[d], -1)
+		case sectionSize - 1:
+			steps[d] = append(steps[d], 1)
+		}
+	}
+	var neighbors []sectionKey
+	for _, dx := range steps[0] {
+		for _, dy := range steps[1] {
+			for _, dz := range steps[2] {
+				if dx == 0 && dy == 0 && dz == 0 {
+					continue
+				}
+				neighbors = append(neighbors, sectionKey{key[0] + dx, key[1] + dy, key[2] + dz})
+			}
+		}
+	}
+	return neighbors
+}
+
+func (r *MeshRenderer) markDirty(section *meshSection) {
+	if section.dirty {
+		return
+	}
+	section.dirty = true
+	r.dirty = append(r.dirty, section)
 }
 
 func (r *MeshRenderer) ToggleWireframe() {
@@ -74,39 +197,261 @@ func (r *MeshRenderer) ToggleWireframe() {
 }
 
 func (r *MeshRenderer) Render() {
-	if r.meshDirty {
-		r.mesher.CreateMesh(r.cubes)
-		r.meshDirty = false
+	r.remeshDirtySections()
+	if r.shadows.enabled() {
+		r.renderShadows()
 	}
 
 	gl.Enable(gl.DEPTH_TEST)
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	gl.UseProgram(r.program)
 	checkGLError("UseProgram")
-	r.mesher.Bind()
-	checkGLError("BindMesh")
 
 	r.SetShaderUniforms()
+	r.cullSections()
 
-	r.mesher.Draw()
+	for _, section := range r.visible {
+		r.setModel(r.program, section.key.origin())
+		section.mesher.Bind()
+		section.mesher.Draw()
+		section.mesher.Unbind()
+	}
 	checkGLError("DrawMesh")
 
-	r.mesher.Unbind()
-	checkGLError("UnbindMesh")
+	r.renderModels()
+	r.renderTranslucent()
 
 	r.drainEvents()
 }
 
+// SetShadows configures the sun's shadow map. Shadows are off until this is
+// called or a ToggleShadows message arrives.
+func (r *MeshRenderer) SetShadows(settings ShadowSettings) {
+	r.shadows.setSettings(settings)
+}
+
+// renderShadows draws every opaque mesh and block model inside the light's
+// frustum into the shadow map, including sections the camera can't see.
+func (r *MeshRenderer) renderShadows() {
+	_, packed := r.mesher.VertexLayout().Attribute("packed0")
+	r.shadows.render(r.camera, r.window, r.Lighting().SunDirection, func(program, packedProgram uint32, frustum primitive.Frustum) {
+		meshProgram := program
+		if packed {
+			meshProgram = packedProgram
+		}
+		gl.UseProgram(meshProgram)
+		for _, section := range r.sections {
+			if !frustum.IntersectsAABB(section.key.bounds()) {
+				continue
+			}
+			r.setModel(meshProgram, section.key.origin())
+			section.mesher.Bind()
+			section.mesher.Draw()
+			section.mesher.Unbind()
+		}
+
+		gl.UseProgram(program)
+		for _, section := range r.sections {
+			if !section.hasModels || !frustum.IntersectsAABB(section.key.bounds()) {
+				continue
+			}
+			r.setModel(program, section.key.origin())
+			section.models.Bind()
+			section.models.Draw()
+			section.models.Unbind()
+		}
+	})
+}
+
+// Metrics reports the geometry of the sections drawn last frame.
+func (r *MeshRenderer) Metrics() Metrics {
+	m := Metrics{Mesher: r.mesher.String(), Sections: len(r.sections), Cull: r.stats}
+	for _, section := range r.visible {
+		m.Vertices += section.vertices
+		m.Triangles += section.triangles
+	}
+	return m
+}
+
+// SectionBounds outlines the cells of every loaded section.
+func (r *MeshRenderer) SectionBounds() []primitive.AABB {
+	bounds := make([]primitive.AABB, 0, len(r.sections))
+	for key := range r.sections {
+		bounds = append(bounds, cellBounds(key.origin(), sectionSize))
+	}
+	return bounds
+}
+
+// DrawNormals queues the normals of every section's meshes on d, moved
+// from section-local space to where the section is drawn.
+func (r *MeshRenderer) DrawNormals(d *DebugDraw, length float32, color component.Color, lifetime time.Duration) {
+	for _, section := range r.sections {
+		model := section.model()
+		d.DrawNormals(section.mesher, model, length, color, lifetime)
+		if section.hasModels {
+			d.DrawNormals(section.models, model, length, color, lifetime)
+		}
+		if section.hasTranslucent {
+			d.DrawNormals(section.translucent, model, length, color, lifetime)
+		}
+	}
+}
+
+// SetOcclusionCulling turns off skipping sections hidden behind solid
+// sections. Frustum culling always applies.
+func (r *MeshRenderer) SetOcclusionCulling(enabled bool) {
+	r.occlusion = enabled
+}
+
+// cullSections collects the sections inside the camera's frustum so the
+// passes below never touch the others. With occlusion culling, only those
+// reachable from the camera's section through open cells are kept.
+func (r *MeshRenderer) cullSections() {
+	frustum := viewFrustum(r.camera, r.window)
+	r.visible = r.visible[:0]
+	if !r.occlusion {
+		for _, section := range r.sections {
+			if frustum.IntersectsAABB(section.key.bounds()) {
+				r.visible = append(r.visible, section)
+			}
+		}
+		r.stats = CullStats{Visible: len(r.visible), Culled: len(r.sections) - len(r.visible)}
+		return
+	}
+
+	inFrustum := 0
+	eye := r.camera.GetPosition()
+	search := occlusion.Search{
+		Lookup: func(k occlusion.Key) occlusion.Visibility {
+			if section, ok := r.sections[sectionKey(k)]; ok {
+				return section.visibility
+			}
+			return occlusion.AllVisible
+		},
+		Accept: func(k occlusion.Key) bool {
+			return frustum.IntersectsAABB(sectionKey(k).bounds())
+		},
+		MaxDistance: occlusionDistance(viewDistance(r.camera)),
+	}
+	for _, k := range search.Visible(occlusion.Key(sectionOf(eye[0], eye[1], eye[2]))) {
+		if section, ok := r.sections[sectionKey(k)]; ok {
+			r.visible = append(r.visible, section)
+		}
+	}
+	for _, section := range r.sections {
+		if frustum.IntersectsAABB(section.key.bounds()) {
+			inFrustum++
+		}
+	}
+	r.stats = CullStats{
+		Visible:  len(r.visible),
+		Culled:   len(r.sections) - inFrustum,
+		Occluded: inFrustum - len(r.visible),
+	}
+}
+
+// CullStats reports how many sections the last frame drew and skipped.
+func (r *MeshRenderer) CullStats() CullStats {
+	return r.stats
+}
+
+func (r *MeshRenderer) remeshDirtySections() {
+	for _, section := range r.dirty {
+		if !section.dirty {
+			continue
+		}
+		section.remesh(r.cache, section.border(r.sections))
+	}
+	r.dirty = r.dirty[:0]
+}
+
+func (r *MeshRenderer) renderModels() {
+	r.useColorProgram()
+	for _, section := range r.visible {
+		if !section.hasModels {
+			continue
+		}
+		r.setModel(r.colorProgram, section.key.origin())
+		section.models.Bind()
+		section.models.Draw()
+		section.models.Unbind()
+	}
+	checkGLError("DrawModels")
+}
+
+func (r *MeshRenderer) useColorProgram() {
+	if r.program == r.colorProgram {
+		return
+	}
+	gl.UseProgram(r.colorProgram)
+	r.setUniforms(r.colorProgram)
+}
+
+// renderTranslucent draws translucent quads after the opaque meshes, blended
+// and without writing depth so they don't hide each other. Sections are
+// drawn far to near and each sorts its own quads.
+func (r *MeshRenderer) renderTranslucent() {
+	eye := r.camera.GetPosition()
+	var sections []*meshSection
+	for _, section := range r.visible {
+		if section.hasTranslucent {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		return
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sectionDistance(sections[i].key, eye) > sectionDistance(sections[j].key, eye)
+	})
+
+	r.useColorProgram()
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+
+	for _, section := range sections {
+		if section.needsSort || eye.Sub(section.lastSortEye).Len() > translucentSortDistance {
+			section.translucent.Sort(eye.Sub(section.key.origin()))
+			section.lastSortEye = eye
+			section.needsSort = false
+		}
+		r.setModel(r.colorProgram, section.key.origin())
+		section.translucent.Bind()
+		section.translucent.Draw()
+		section.translucent.Unbind()
+	}
+	checkGLError("DrawTranslucent")
+
+	gl.DepthMask(true)
+	gl.Disable(gl.BLEND)
+}
+
+func sectionDistance(key sectionKey, eye mgl32.Vec3) float32 {
+	half := float32(sectionSize) / 2
+	return key.origin().Add(mgl32.Vec3{half, half, half}).Sub(eye).LenSqr()
+}
+
+func (r *MeshRenderer) setModel(program uint32, origin mgl32.Vec3) {
+	model := mgl32.Translate3D(origin[0], origin[1], origin[2])
+	modelLoc := gl.GetUniformLocation(program, gl.Str("model\x00"))
+	gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
+}
+
 func (r *MeshRenderer) SetShaderUniforms() {
-	width, height := r.window.GetSize()
+	r.setUniforms(r.program)
+}
+
+func (r *MeshRenderer) setUniforms(program uint32) {
 	view := r.camera.GetViewMatrix()
-	projection := mgl32.Perspective(mgl32.DegToRad(45), float32(width)/float32(height), 0.1, 100.0)
+	projection := perspective(r.camera, r.window)
 
-	viewLoc := gl.GetUniformLocation(r.program, gl.Str("view\x00"))
-	projLoc := gl.GetUniformLocation(r.program, gl.Str("projection\x00"))
+	viewLoc := gl.GetUniformLocation(program, gl.Str("view\x00"))
+	projLoc := gl.GetUniformLocation(program, gl.Str("projection\x00"))
 
 	gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
 	gl.UniformMatrix4fv(projLoc, 1, false, &projection[0])
+
+	r.setSceneUniforms(program, &r.shadows, r.camera.GetPosition())
 	checkGLError("SetShaderUniforms")
 }
 
@@ -114,8 +459,11 @@ func (r *MeshRenderer) drainEvents() {
 	for {
 		select {
 		case event := <-r.events:
-			if event == "ToggleWireframe" {
+			switch event {
+			case "ToggleWireframe":
 				r.ToggleWireframe()
+			case "ToggleShadows":
+				r.shadows.toggle()
 			}
 		default:
 			return // Exit the loop when there are no more events
@@ -138,7 +486,10 @@ func (r *MeshRenderer) subscribeToEvents() {
 			if !ok {
 				return
 			}
-			if m.GetTopic() == "ToggleWireframe" {
+			if r.handleSceneMessage(m) {
+				continue
+			}
+			if m.GetTopic() == "ToggleWireframe" || m.GetTopic() == "ToggleShadows" {
 				r.events <- m.GetTopic()
 			}
 		default:
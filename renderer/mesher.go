@@ -8,8 +8,7 @@ import (
 )
 
 type CubeMesher struct {
-	vao      uint32
-	vbo      uint32
+	meshBuffers
 	vertices []float32
 }
 
@@ -18,9 +17,17 @@ func NewCubeMesher() *CubeMesher {
 }
 
 func (m *CubeMesher) CreateMesh(cubes []primitive.Cube) {
+	m.GenerateMesh(cubes)
+	m.setupBuffers()
+}
+
+func (m *CubeMesher) GenerateMesh(cubes []primitive.Cube) {
 	m.vertices = nil
 	m.createCube(cubes)
-	m.setupBuffers()
+}
+
+func (m *CubeMesher) VertexLayout() VertexLayout {
+	return PositionColorNormalLayout
 }
 
 func (m *CubeMesher) Bind() {
@@ -34,7 +41,7 @@ func (m *CubeMesher) Unbind() {
 func (m *CubeMesher) Draw() {
 	m.EnableBackfaceCulling()
 	gl.BindVertexArray(m.vao)
-	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(m.vertices)/6))
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(m.vertices)/9))
 	gl.BindVertexArray(0)
 }
 
@@ -46,58 +53,71 @@ func (m *CubeMesher) String() string {
 	return fmt.Sprintf("Vertices: %v", m.vertices)
 }
 
+func (m *CubeMesher) Clone() Mesher {
+	return NewCubeMesher()
+}
+
+func (m *CubeMesher) CacheKey() string {
+	return "cube/1"
+}
+
+func (m *CubeMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.vertices = append(m.vertices[:0], vertices...)
+	m.setupBuffers()
+}
+
 func (m *CubeMesher) createCube(cubes []primitive.Cube) {
 	for _, cube := range cubes {
 		color := cube.Color
-		// Cube vertices positions and colors
+		// Cube vertices positions, colors and normals
 		cubeVertices := []float32{
 			// Front face (CCW order)
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 0, 1,
 
 			// Back face (CCW order)
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 0, -1,
 
 			// Left face (CCW order)
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], -1, 0, 0,
 
 			// Right face (CCW order)
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 1, 0, 0,
 
 			// Top face (CCW order)
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
+			cube.X + cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
+			cube.X - cube.Size/2, cube.Y + cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, 1, 0,
 
 			// Bottom face (CCW order)
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2],
-			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2],
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
+			cube.X + cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z + cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
+			cube.X - cube.Size/2, cube.Y - cube.Size/2, cube.Z - cube.Size/2, color[0], color[1], color[2], 0, -1, 0,
 		}
 
 		m.vertices = append(m.vertices, cubeVertices...)
@@ -105,23 +125,7 @@ func (m *CubeMesher) createCube(cubes []primitive.Cube) {
 }
 
 func (m *CubeMesher) setupBuffers() {
-	var vao, vbo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(m.vertices)*4, gl.Ptr(m.vertices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 6*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, 6*4, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	m.vao = vao
-	m.vbo = vbo
+	m.upload(m.vertices, m.VertexLayout())
 }
 
 func (m *CubeMesher) EnableBackfaceCulling() {
@@ -0,0 +1,34 @@
+package renderer
+
+// Metrics is what a renderer reports about its last frame, for the debug
+// HUD.
+type Metrics struct {
+	// Mesher names the mesher in use, or is empty when there isn't one.
+	Mesher string
+	// Vertices and Triangles count the geometry drawn last frame.
+	Vertices  int
+	Triangles int
+	// Sections counts the sections or chunks the renderer has loaded.
+	Sections int
+	Cull     CullStats
+}
+
+// MetricsReporter is a renderer that reports Metrics. Call Metrics on the
+// render thread, after Render.
+type MetricsReporter interface {
+	Metrics() Metrics
+}
+
+// meshSize counts a mesher's vertices and triangles from its mesh data.
+func meshSize(m Mesher) (vertices, triangles int) {
+	data, indices := m.GetMesh()
+	stride := m.VertexLayout().Floats()
+	if stride == 0 {
+		return 0, 0
+	}
+	vertices = len(data) / stride
+	if indices == nil {
+		return vertices, vertices / 3
+	}
+	return vertices, len(indices) / 3
+}
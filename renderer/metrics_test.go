@@ -0,0 +1,36 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestMeshSize(t *testing.T) {
+	m := NewCubeMesher()
+	m.GenerateMesh([]primitive.Cube{{Size: 1}})
+	vertices, triangles := meshSize(m)
+	if vertices != 36 || triangles != 12 {
+		t.Errorf("one unindexed cube = %d vertices, %d triangles, want 36, 12", vertices, triangles)
+	}
+}
+
+func TestSoftwareRendererMetrics(t *testing.T) {
+	r := NewSoftwareRenderer(NewGreedyMesher(), 32, 32)
+	r.SetCamera(testCamera{eye: mgl32.Vec3{4, 12, 24}, target: mgl32.Vec3{4, 4, 4}})
+	for _, cube := range scene.Solid(8) {
+		r.AddCube(cube)
+	}
+	r.Render()
+
+	m := r.Metrics()
+	if m.Mesher != NewGreedyMesher().String() || m.Sections != 1 || m.Cull.Visible != 1 {
+		t.Errorf("Metrics = %+v", m)
+	}
+	// A greedy solid block is one quad per side.
+	if m.Triangles != 12 {
+		t.Errorf("Triangles = %d, want 12", m.Triangles)
+	}
+}
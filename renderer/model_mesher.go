@@ -0,0 +1,216 @@
+package renderer
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ModelMesher emits the boxes and crossed quads of cubes whose model isn't a
+// full block. Box faces are dropped where the same model or a neighbour
+// covers them. Cubes span [x, x+1] on each axis, like the greedy meshers.
+type ModelMesher struct {
+	meshBuffers
+	vertices []float32
+	indices  []uint32
+}
+
+func NewModelMesher() *ModelMesher {
+	return &ModelMesher{}
+}
+
+// CreateMesh takes every cube in the scene. Only cubes with a non-full model
+// produce geometry; the rest are used for culling.
+func (m *ModelMesher) CreateMesh(cubes []primitive.Cube) {
+	m.CreateMeshWithBorder(cubes, nil)
+}
+
+// CreateMeshWithBorder is CreateMesh for one section of a larger world.
+// border holds cubes of the neighbouring sections, in the same space, that
+// can hide faces on the section's edge; they produce no geometry.
+func (m *ModelMesher) CreateMeshWithBorder(cubes, border []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, border)
+	m.setupBuffers()
+}
+
+func (m *ModelMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, nil)
+}
+
+func (m *ModelMesher) GenerateMeshWithBorder(cubes, border []primitive.Cube) {
+	m.vertices = m.vertices[:0]
+	m.indices = m.indices[:0]
+
+	cells := make(map[[3]int]primitive.Cube, len(cubes)+len(border))
+	for _, cubes := range [][]primitive.Cube{border, cubes} {
+		for _, cube := range cubes {
+			if cube.ShouldHide {
+				continue
+			}
+			cells[[3]int{int(cube.X), int(cube.Y), int(cube.Z)}] = cube
+		}
+	}
+
+	for _, cube := range cubes {
+		if cube.IsFull() || cube.Translucent || cube.ShouldHide {
+			continue
+		}
+		if cube.Model.Cross {
+			m.addCross(cube)
+			continue
+		}
+
+		pos := [3]int{int(cube.X), int(cube.Y), int(cube.Z)}
+		occluders := m.occluders(pos, cube, cells)
+		for _, box := range cube.Model.Boxes {
+			for d := 0; d < 3; d++ {
+				for _, positive := range []bool{false, true} {
+					if faceCovered(box, d, positive, occluders) {
+						continue
+					}
+					m.addBoxFace(cube, box, d, positive)
+				}
+			}
+		}
+	}
+}
+
+// occluders collects, in the cube's cell space, the boxes of its own model
+// and of opaque neighbours that can cover one of its faces.
+func (m *ModelMesher) occluders(pos [3]int, cube primitive.Cube, cells map[[3]int]primitive.Cube) []primitive.AABB {
+	boxes := append([]primitive.AABB(nil), cube.Model.Boxes...)
+	for d := 0; d < 3; d++ {
+		for _, step := range []int{-1, 1} {
+			neighbor := pos
+			neighbor[d] += step
+			other, ok := cells[neighbor]
+			if !ok || other.Size == 0 || other.Translucent || (other.Model != nil && other.Model.Cross) {
+				continue
+			}
+			for _, b := range other.Boxes() {
+				boxes = append(boxes, b.Translate(mgl32.Vec3{cube.X, cube.Y, cube.Z}.Mul(-1)))
+			}
+		}
+	}
+	return boxes
+}
+
+// faceCovered reports whether a box on the far side of the face plane covers
+// the whole face.
+func faceCovered(box primitive.AABB, d int, positive bool, occluders []primitive.AABB) bool {
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	for _, o := range occluders {
+		if positive && o.Min[d] != box.Max[d] || !positive && o.Max[d] != box.Min[d] {
+			continue
+		}
+		if o.Min[u] <= box.Min[u] && o.Max[u] >= box.Max[u] && o.Min[v] <= box.Min[v] && o.Max[v] >= box.Max[v] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ModelMesher) addBoxFace(cube primitive.Cube, box primitive.AABB, d int, positive bool) {
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	normal := mgl32.Vec3{}
+	p := box.Min
+	if positive {
+		p[d] = box.Max[d]
+		normal[d] = 1
+	} else {
+		normal[d] = -1
+	}
+	p = p.Add(mgl32.Vec3{cube.X, cube.Y, cube.Z})
+
+	w := box.Max[u] - box.Min[u]
+	h := box.Max[v] - box.Min[v]
+	var corners [4]mgl32.Vec3
+	for i, c := range [4][2]float32{{0, 0}, {w, 0}, {w, h}, {0, h}} {
+		corners[i] = p
+		corners[i][u] += c[0]
+		corners[i][v] += c[1]
+	}
+
+	// The corners run counter-clockwise seen from +d.
+	if !positive {
+		corners[1], corners[3] = corners[3], corners[1]
+	}
+	m.addQuad(corners, cube, normal)
+}
+
+// addCross adds two diagonal quads through the cell, each drawn from both
+// sides.
+func (m *ModelMesher) addCross(cube primitive.Cube) {
+	p := mgl32.Vec3{cube.X, cube.Y, cube.Z}
+	up := mgl32.Vec3{0, 1, 0}
+	diagonals := [2][2]mgl32.Vec3{
+		{{0, 0, 0}, {1, 0, 1}},
+		{{1, 0, 0}, {0, 0, 1}},
+	}
+	for _, diag := range diagonals {
+		a, b := p.Add(diag[0]), p.Add(diag[1])
+		front := [4]mgl32.Vec3{a, b, b.Add(up), a.Add(up)}
+		back := [4]mgl32.Vec3{a, a.Add(up), b.Add(up), b}
+		m.addQuad(front, cube, up)
+		m.addQuad(back, cube, up)
+	}
+}
+
+func (m *ModelMesher) addQuad(corners [4]mgl32.Vec3, cube primitive.Cube, normal mgl32.Vec3) {
+	idx := uint32(len(m.vertices) / 9)
+	for _, c := range corners {
+		m.vertices = append(m.vertices,
+			c[0], c[1], c[2], cube.Color[0], cube.Color[1], cube.Color[2], normal[0], normal[1], normal[2],
+		)
+	}
+	m.indices = append(m.indices,
+		idx, idx+1, idx+2,
+		idx, idx+2, idx+3,
+	)
+}
+
+func (m *ModelMesher) VertexLayout() VertexLayout {
+	return PositionColorNormalLayout
+}
+
+func (m *ModelMesher) Bind() {
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *ModelMesher) Unbind() {
+	gl.BindVertexArray(0)
+}
+
+func (m *ModelMesher) Draw() {
+	m.EnableBackfaceCulling()
+	gl.BindVertexArray(m.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(0)
+}
+
+func (m *ModelMesher) GetMesh() ([]float32, []uint32) {
+	return m.vertices, m.indices
+}
+
+func (m *ModelMesher) String() string {
+	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
+}
+
+func (m *ModelMesher) Clone() Mesher {
+	return NewModelMesher()
+}
+
+func (m *ModelMesher) setupBuffers() {
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.STATIC_DRAW)
+}
+
+func (m *ModelMesher) EnableBackfaceCulling() {
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.FrontFace(gl.CCW)
+}
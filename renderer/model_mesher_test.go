@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+func TestModelMesherHidesFacesAgainstFullNeighbors(t *testing.T) {
+	block := func(x, y, z float32, model *primitive.BlockModel) primitive.Cube {
+		cube := primitive.Cube{Size: 1, Model: model}
+		cube.X, cube.Y, cube.Z = x, y, z
+		return cube
+	}
+	faces := func(cubes ...primitive.Cube) int {
+		m := NewModelMesher()
+		m.GenerateMesh(cubes)
+		return len(m.indices) / 6
+	}
+
+	slab := block(0, 0, 0, primitive.SlabModel)
+	water := block(1, 0, 0, nil)
+	water.Translucent, water.Opacity = true, 0.5
+	tests := []struct {
+		name  string
+		cubes []primitive.Cube
+		want  int
+	}{
+		{"alone", []primitive.Cube{slab}, 6},
+		{"on a full block", []primitive.Cube{slab, block(0, -1, 0, nil)}, 5},
+		// The slab's top is half a block below the cube above it.
+		{"under a full block", []primitive.Cube{slab, block(0, 1, 0, nil)}, 6},
+		{"beside a full block", []primitive.Cube{slab, block(1, 0, 0, nil)}, 5},
+		{"boxed in", []primitive.Cube{slab, block(0, -1, 0, nil), block(1, 0, 0, nil), block(-1, 0, 0, nil), block(0, 0, 1, nil), block(0, 0, -1, nil)}, 1},
+		{"beside a slab", []primitive.Cube{slab, block(1, 0, 0, primitive.SlabModel)}, 10},
+		{"beside a translucent block", []primitive.Cube{slab, water}, 6},
+		{"beside a plant", []primitive.Cube{slab, block(1, 0, 0, primitive.CrossModel)}, 6 + 4},
+	}
+	for _, tt := range tests {
+		if got := faces(tt.cubes...); got != tt.want {
+			t.Errorf("%s: %d faces, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/sirupsen/logrus"
+)
+
+// PackedVertex is a voxel vertex in the form stored by PackVertex.
+//
+// The first word holds the position (6 bits per axis), the normal index
+// (3 bits), ambient occlusion (2 bits) and light (4 bits). The second holds
+// the colour as 8 bits per channel and an 8 bit texture layer.
+type PackedVertex struct {
+	X, Y, Z uint8
+	Normal  uint8
+	AO      uint8
+	Light   uint8
+	Color   [3]uint8
+	Layer   uint8
+}
+
+const (
+	maxPackedPosition = 63
+	maxPackedAO       = 3
+	maxPackedLight    = 15
+)
+
+// packedNormals is indexed by PackedVertex.Normal. The vertex shader has the
+// same table.
+var packedNormals = [6][3]float32{
+	{1, 0, 0}, {-1, 0, 0},
+	{0, 1, 0}, {0, -1, 0},
+	{0, 0, 1}, {0, 0, -1},
+}
+
+func PackVertex(v PackedVertex) [2]uint32 {
+	var w [2]uint32
+	w[0] = uint32(v.X&0x3f) |
+		uint32(v.Y&0x3f)<<6 |
+		uint32(v.Z&0x3f)<<12 |
+		uint32(v.Normal&0x7)<<18 |
+		uint32(v.AO&0x3)<<21 |
+		uint32(v.Light&0xf)<<23
+	w[1] = uint32(v.Color[0]) |
+		uint32(v.Color[1])<<8 |
+		uint32(v.Color[2])<<16 |
+		uint32(v.Layer)<<24
+	return w
+}
+
+func UnpackVertex(w [2]uint32) PackedVertex {
+	return PackedVertex{
+		X:      uint8(w[0] & 0x3f),
+		Y:      uint8(w[0] >> 6 & 0x3f),
+		Z:      uint8(w[0] >> 12 & 0x3f),
+		Normal: uint8(w[0] >> 18 & 0x7),
+		AO:     uint8(w[0] >> 21 & 0x3),
+		Light:  uint8(w[0] >> 23 & 0xf),
+		Color:  [3]uint8{uint8(w[1]), uint8(w[1] >> 8), uint8(w[1] >> 16)},
+		Layer:  uint8(w[1] >> 24),
+	}
+}
+
+// NormalIndex returns the packed index of the axis-aligned normal n. ok is
+// false, and the index that of +X, when n isn't one of the six axes.
+func NormalIndex(n [3]float32) (index uint8, ok bool) {
+	for i, pn := range packedNormals {
+		if pn == n {
+			return uint8(i), true
+		}
+	}
+	return 0, false
+}
+
+// PackedNormal is the inverse of NormalIndex.
+func PackedNormal(i uint8) [3]float32 {
+	return packedNormals[i%6]
+}
+
+// packFloatVertex converts a PositionColorNormalLayout vertex. Positions are
+// rounded to whole cells and clamped to [0, 63]. ok is false when the normal
+// isn't axis-aligned.
+func packFloatVertex(v []float32) (PackedVertex, bool) {
+	normal, ok := NormalIndex([3]float32{v[6], v[7], v[8]})
+	return PackedVertex{
+		X:      packPosition(v[0]),
+		Y:      packPosition(v[1]),
+		Z:      packPosition(v[2]),
+		Color:  [3]uint8{packChannel(v[3]), packChannel(v[4]), packChannel(v[5])},
+		Normal: normal,
+		AO:     maxPackedAO,
+		Light:  maxPackedLight,
+	}, ok
+}
+
+func packPosition(p float32) uint8 {
+	return uint8(math.Max(0, math.Min(maxPackedPosition, math.Round(float64(p)))))
+}
+
+func packChannel(c float32) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(float64(c)*255))))
+}
+
+// Position returns the vertex position in cells.
+func (v PackedVertex) Position() [3]float32 {
+	return [3]float32{float32(v.X), float32(v.Y), float32(v.Z)}
+}
+
+func (v PackedVertex) ColorValue() component.Color {
+	return component.Color{float32(v.Color[0]) / 255, float32(v.Color[1]) / 255, float32(v.Color[2]) / 255}
+}
+
+// PackedMesher wraps a mesher that outputs PositionColorNormalLayout and
+// uploads its mesh as PackedLayout, 8 bytes per vertex instead of 36.
+// Positions must be whole numbers in [0, 63], which holds for the greedy
+// meshers within a section. Normals must be axis-aligned; other normals are
+// packed as +X and logged once per mesher.
+type PackedMesher struct {
+	meshBuffers
+	inner   Mesher
+	packed  []uint32
+	indices []uint32
+	warned  bool
+}
+
+func NewPackedMesher(inner Mesher) *PackedMesher {
+	return &PackedMesher{inner: inner}
+}
+
+func (m *PackedMesher) CreateMesh(cubes []primitive.Cube) {
+	m.GenerateMesh(cubes)
+	m.setupBuffers()
+}
+
+func (m *PackedMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.inner.GenerateMesh(cubes)
+	vertices, indices := m.inner.GetMesh()
+	stride := m.inner.VertexLayout().Floats()
+
+	m.packed = m.packed[:0]
+	skewed := 0
+	for i := 0; i+stride <= len(vertices); i += stride {
+		v, ok := packFloatVertex(vertices[i : i+stride])
+		if !ok {
+			skewed++
+		}
+		w := PackVertex(v)
+		m.packed = append(m.packed, w[0], w[1])
+	}
+	m.indices = append(m.indices[:0], indices...)
+
+	if skewed > 0 && !m.warned {
+		m.warned = true
+		logrus.Warnf("PackedMesher: %d vertices from %T have normals that aren't axis-aligned; packing them as +X", skewed, m.inner)
+	}
+}
+
+// PackedVertices returns the packed words, two per vertex.
+func (m *PackedMesher) PackedVertices() []uint32 {
+	return m.packed
+}
+
+func (m *PackedMesher) VertexLayout() VertexLayout {
+	return PackedLayout
+}
+
+func (m *PackedMesher) Shaders() (string, string) {
+	return "packed_vertex_shader.glsl", "cube_fragment_shader.glsl"
+}
+
+func (m *PackedMesher) Bind() {
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *PackedMesher) Unbind() {
+	gl.BindVertexArray(0)
+}
+
+func (m *PackedMesher) Draw() {
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.FrontFace(gl.CCW)
+	gl.BindVertexArray(m.vao)
+	if m.indices == nil {
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(m.packed)/2))
+	} else {
+		gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	}
+	gl.BindVertexArray(0)
+}
+
+// GetMesh returns the packed words reinterpreted as float32s, so the slice
+// has the size of the uploaded buffer. Use PackedVertices to read them.
+func (m *PackedMesher) GetMesh() ([]float32, []uint32) {
+	if len(m.packed) == 0 {
+		return nil, m.indices
+	}
+	return unsafe.Slice((*float32)(unsafe.Pointer(&m.packed[0])), len(m.packed)), m.indices
+}
+
+func (m *PackedMesher) String() string {
+	return fmt.Sprintf("Packed: %v\nIndices: %v", m.packed, m.indices)
+}
+
+func (m *PackedMesher) Clone() Mesher {
+	return NewPackedMesher(m.inner.Clone())
+}
+
+func (m *PackedMesher) CacheKey() string {
+	if inner, ok := m.inner.(CacheableMesher); ok {
+		return "packed/1/" + inner.CacheKey()
+	}
+	return fmt.Sprintf("packed/1/%T", m.inner)
+}
+
+func (m *PackedMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.packed = m.packed[:0]
+	for _, v := range vertices {
+		m.packed = append(m.packed, math.Float32bits(v))
+	}
+	m.indices = append([]uint32(nil), indices...)
+	m.setupBuffers()
+}
+
+func (m *PackedMesher) setupBuffers() {
+	vertices, indices := m.GetMesh()
+	if indices == nil {
+		m.upload(vertices, m.VertexLayout())
+		return
+	}
+	m.uploadIndexed(vertices, indices, m.VertexLayout(), gl.STATIC_DRAW)
+}
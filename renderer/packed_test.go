@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPackVertexRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		v := PackedVertex{
+			X:      uint8(rng.Intn(maxPackedPosition + 1)),
+			Y:      uint8(rng.Intn(maxPackedPosition + 1)),
+			Z:      uint8(rng.Intn(maxPackedPosition + 1)),
+			Normal: uint8(rng.Intn(len(packedNormals))),
+			AO:     uint8(rng.Intn(maxPackedAO + 1)),
+			Light:  uint8(rng.Intn(maxPackedLight + 1)),
+			Color:  [3]uint8{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256))},
+			Layer:  uint8(rng.Intn(256)),
+		}
+		if got := UnpackVertex(PackVertex(v)); got != v {
+			t.Fatalf("UnpackVertex(PackVertex(%+v)) = %+v", v, got)
+		}
+	}
+}
+
+func TestPackedMesherRoundTrip(t *testing.T) {
+	cubes := scene.Terrain(16, 1)
+	greedy := NewGreedyMesher()
+	greedy.GenerateMesh(cubes)
+	packed := NewPackedMesher(NewGreedyMesher())
+	packed.GenerateMesh(cubes)
+
+	words := packed.PackedVertices()
+	if len(words) != len(greedy.vertices)/9*2 {
+		t.Fatalf("packed %d words for %d vertices", len(words), len(greedy.vertices)/9)
+	}
+	for i := 0; i < len(words)/2; i++ {
+		want := greedy.vertices[i*9 : i*9+9]
+		got := UnpackVertex([2]uint32{words[i*2], words[i*2+1]})
+
+		if p := got.Position(); p != [3]float32{want[0], want[1], want[2]} {
+			t.Fatalf("vertex %d position = %v, want %v", i, p, want[0:3])
+		}
+		if n := PackedNormal(got.Normal); n != [3]float32{want[6], want[7], want[8]} {
+			t.Fatalf("vertex %d normal = %v, want %v", i, n, want[6:9])
+		}
+		c := got.ColorValue()
+		for j := 0; j < 3; j++ {
+			if math.Abs(float64(c[j]-want[3+j])) > 0.5/255 {
+				t.Fatalf("vertex %d color = %v, want %v", i, c, want[3:6])
+			}
+		}
+	}
+
+	_, indices := packed.GetMesh()
+	if len(indices) != len(greedy.indices) {
+		t.Fatalf("index count = %d, want %d", len(indices), len(greedy.indices))
+	}
+}
+
+func TestPackedMesherCopiesIndices(t *testing.T) {
+	packed := NewPackedMesher(NewBinaryGreedyMesher())
+	packed.GenerateMesh(scene.Terrain(16, 1))
+	_, indices := packed.GetMesh()
+	want := append([]uint32(nil), indices...)
+
+	// The inner mesher reuses its index slice for the next mesh.
+	packed.inner.GenerateMesh(scene.Terrain(16, 2))
+	_, got := packed.GetMesh()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("index %d changed from %d to %d when the inner mesher remeshed", i, want[i], got[i])
+		}
+	}
+}
+
+func TestPackedMesherWarnsAboutSkewedNormals(t *testing.T) {
+	if _, ok := NormalIndex([3]float32{0.6, 0.8, 0}); ok {
+		t.Error("a diagonal normal has a packed index")
+	}
+
+	var log bytes.Buffer
+	logrus.SetOutput(&log)
+	defer logrus.SetOutput(os.Stderr)
+
+	packed := NewPackedMesher(NewSurfaceNetsMesher())
+	packed.GenerateMesh(scene.Terrain(8, 1))
+	packed.GenerateMesh(scene.Terrain(8, 1))
+	if n := strings.Count(log.String(), "axis-aligned"); n != 1 {
+		t.Errorf("logged %d warnings about surface nets normals, want 1: %s", n, log.String())
+	}
+
+	log.Reset()
+	NewPackedMesher(NewGreedyMesher()).GenerateMesh(scene.Terrain(8, 1))
+	if log.Len() != 0 {
+		t.Errorf("greedy normals were logged: %s", log.String())
+	}
+}
@@ -0,0 +1,451 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/sirupsen/logrus"
+)
+
+// PostInput is what a PostPass reads: the previous pass's colour, or the
+// scene's for the first pass, and the scene's depth.
+type PostInput struct {
+	Color, Depth  uint32
+	Width, Height int
+}
+
+// PostPass is one step of a PostProcessor. Apply draws into the bound
+// framebuffer. A vertex array is bound for a fullscreen triangle, drawn
+// with gl.DrawArrays(gl.TRIANGLES, 0, 3) from post_vertex_shader.glsl.
+type PostPass interface {
+	Name() string
+	// Resize is called before the first Apply and whenever the screen
+	// changes size.
+	Resize(width, height int)
+	Apply(input PostInput)
+}
+
+// PostProcessor renders the scene into an offscreen HDR target and then
+// runs its passes over it, in order, ending on the framebuffer that was
+// bound at Begin. With no passes enabled the scene is copied straight
+// through.
+type PostProcessor struct {
+	passes  []postEntry
+	scene   renderTarget
+	targets [2]renderTarget
+	vao     uint32
+
+	width, height int
+	output        uint32
+}
+
+type postEntry struct {
+	pass    PostPass
+	enabled bool
+}
+
+// NewPostProcessor runs passes in the order given. DefaultPostPasses is a
+// starting point.
+func NewPostProcessor(passes ...PostPass) *PostProcessor {
+	p := &PostProcessor{}
+	for _, pass := range passes {
+		p.Add(pass)
+	}
+	return p
+}
+
+// Add appends pass, enabled, or replaces the pass of the same name in
+// place.
+func (p *PostProcessor) Add(pass PostPass) {
+	for i, e := range p.passes {
+		if e.pass.Name() == pass.Name() {
+			p.passes[i].pass = pass
+			if p.width > 0 {
+				pass.Resize(p.width, p.height)
+			}
+			return
+		}
+	}
+	p.passes = append(p.passes, postEntry{pass: pass, enabled: true})
+	if p.width > 0 {
+		pass.Resize(p.width, p.height)
+	}
+}
+
+// Remove drops the named pass and reports whether there was one.
+func (p *PostProcessor) Remove(name string) bool {
+	for i, e := range p.passes {
+		if e.pass.Name() == name {
+			p.passes = append(p.passes[:i], p.passes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetEnabled turns the named pass on or off and reports whether there was
+// one.
+func (p *PostProcessor) SetEnabled(name string, enabled bool) bool {
+	for i, e := range p.passes {
+		if e.pass.Name() == name {
+			p.passes[i].enabled = enabled
+			return true
+		}
+	}
+	return false
+}
+
+// Pass returns the named pass so its settings can be changed.
+func (p *PostProcessor) Pass(name string) (PostPass, bool) {
+	for _, e := range p.passes {
+		if e.pass.Name() == name {
+			return e.pass, true
+		}
+	}
+	return nil, false
+}
+
+// Passes lists the pass names in order.
+func (p *PostProcessor) Passes() []string {
+	names := make([]string, len(p.passes))
+	for i, e := range p.passes {
+		names[i] = e.pass.Name()
+	}
+	return names
+}
+
+func (p *PostProcessor) enabledPasses() []PostPass {
+	var passes []PostPass
+	for _, e := range p.passes {
+		if e.enabled {
+			passes = append(passes, e.pass)
+		}
+	}
+	return passes
+}
+
+// Begin redirects drawing into the scene target, resizing the targets and
+// passes first if width or height changed. Clear after calling it.
+func (p *PostProcessor) Begin(width, height int) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if p.vao == 0 {
+		gl.GenVertexArrays(1, &p.vao)
+	}
+	if width != p.width || height != p.height {
+		p.resize(width, height)
+	}
+
+	var output int32
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &output)
+	p.output = uint32(output)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, p.scene.fbo)
+	gl.Viewport(0, 0, int32(width), int32(height))
+}
+
+func (p *PostProcessor) resize(width, height int) {
+	p.scene.delete()
+	for i := range p.targets {
+		p.targets[i].delete()
+	}
+
+	var err error
+	if p.scene, err = newRenderTarget(width, height, true); err != nil {
+		logrus.Errorln("post-processing:", err)
+	}
+	for i := range p.targets {
+		if p.targets[i], err = newRenderTarget(width, height, false); err != nil {
+			logrus.Errorln("post-processing:", err)
+		}
+	}
+	p.width, p.height = width, height
+	for _, e := range p.passes {
+		e.pass.Resize(width, height)
+	}
+}
+
+// End runs the enabled passes over the scene and draws the result to the
+// framebuffer that was bound at Begin.
+func (p *PostProcessor) End() {
+	if p.width == 0 {
+		return
+	}
+	passes := p.enabledPasses()
+	if len(passes) == 0 {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, p.scene.fbo)
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, p.output)
+		w, h := int32(p.width), int32(p.height)
+		gl.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, p.output)
+		return
+	}
+
+	// Fullscreen passes ignore wireframe, depth and blending left on by the
+	// renderers.
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	blend := gl.IsEnabled(gl.BLEND)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+	gl.BindVertexArray(p.vao)
+
+	input := PostInput{Color: p.scene.color, Depth: p.scene.depth, Width: p.width, Height: p.height}
+	for i, pass := range passes {
+		if i == len(passes)-1 {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, p.output)
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, p.targets[i%2].fbo)
+		}
+		gl.Viewport(0, 0, int32(p.width), int32(p.height))
+		pass.Apply(input)
+		input.Color = p.targets[i%2].color
+	}
+
+	gl.BindVertexArray(0)
+	gl.Enable(gl.DEPTH_TEST)
+	if blend {
+		gl.Enable(gl.BLEND)
+	}
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	checkGLError("PostProcessor")
+}
+
+// renderTarget is a floating point colour texture, with a depth texture
+// for the scene.
+type renderTarget struct {
+	fbo, color, depth uint32
+	width, height     int
+}
+
+func newRenderTarget(width, height int, withDepth bool) (renderTarget, error) {
+	t := renderTarget{width: width, height: height}
+
+	gl.GenFramebuffers(1, &t.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+
+	gl.GenTextures(1, &t.color)
+	gl.BindTexture(gl.TEXTURE_2D, t.color)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA16F, int32(width), int32(height), 0, gl.RGBA, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.color, 0)
+
+	if withDepth {
+		gl.GenTextures(1, &t.depth)
+		gl.BindTexture(gl.TEXTURE_2D, t.depth)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT24, int32(width), int32(height), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, t.depth, 0)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		t.delete()
+		return renderTarget{}, fmt.Errorf("render target incomplete: 0x%x", status)
+	}
+	return t, nil
+}
+
+func (t *renderTarget) delete() {
+	if t.fbo != 0 {
+		gl.DeleteFramebuffers(1, &t.fbo)
+	}
+	if t.color != 0 {
+		gl.DeleteTextures(1, &t.color)
+	}
+	if t.depth != 0 {
+		gl.DeleteTextures(1, &t.depth)
+	}
+	*t = renderTarget{}
+}
+
+// ShaderPass is a PostPass drawn by one fragment shader from ShaderFS. The
+// shader samples the previous pass from screenTexture, the scene's depth
+// from depthTexture, and gets texelSize and any values given to Set.
+type ShaderPass struct {
+	name    string
+	program uint32
+	floats  map[string]float32
+}
+
+// NewShaderPass compiles fragment, a file in ShaderFS, with
+// post_vertex_shader.glsl.
+func NewShaderPass(name, fragment string) (*ShaderPass, error) {
+	program, err := shader.NewProgramFromFiles("post_vertex_shader.glsl", fragment)
+	if err != nil {
+		return nil, err
+	}
+	return &ShaderPass{name: name, program: program, floats: make(map[string]float32)}, nil
+}
+
+// mustShaderPass is for the built in passes, whose shaders are embedded.
+func mustShaderPass(name, fragment string) *ShaderPass {
+	pass, err := NewShaderPass(name, fragment)
+	if err != nil {
+		logrus.Fatalf("failed to create %s pass: %v", name, err)
+	}
+	return pass
+}
+
+func (s *ShaderPass) Name() string {
+	return s.name
+}
+
+// Set gives a float uniform a value for every later Apply.
+func (s *ShaderPass) Set(uniform string, value float32) {
+	s.floats[uniform] = value
+}
+
+func (s *ShaderPass) Get(uniform string) float32 {
+	return s.floats[uniform]
+}
+
+func (s *ShaderPass) Resize(width, height int) {}
+
+func (s *ShaderPass) Apply(input PostInput) {
+	gl.UseProgram(s.program)
+	bindPostInput(s.program, input)
+	for name, value := range s.floats {
+		gl.Uniform1f(gl.GetUniformLocation(s.program, gl.Str(name+"\x00")), value)
+	}
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
+
+func bindPostInput(program uint32, input PostInput) {
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, input.Color)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, input.Depth)
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("screenTexture\x00")), 0)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("depthTexture\x00")), 1)
+	gl.Uniform2f(gl.GetUniformLocation(program, gl.Str("texelSize\x00")), 1/float32(input.Width), 1/float32(input.Height))
+}
+
+// NewToneMapPass maps HDR colour into the displayable range with the ACES
+// curve. Raise "exposure" to brighten.
+func NewToneMapPass() *ShaderPass {
+	pass := mustShaderPass("tonemap", "tonemap_fragment_shader.glsl")
+	pass.Set("exposure", 1)
+	return pass
+}
+
+// NewGammaPass encodes linear colour for the screen. Colours in this
+// engine are picked on screen, so it brightens scenes; leave it out unless
+// they were picked in linear space.
+func NewGammaPass() *ShaderPass {
+	pass := mustShaderPass("gamma", "gamma_fragment_shader.glsl")
+	pass.Set("gamma", 2.2)
+	return pass
+}
+
+// NewVignettePass darkens the corners by "strength" starting at "radius".
+func NewVignettePass() *ShaderPass {
+	pass := mustShaderPass("vignette", "vignette_fragment_shader.glsl")
+	pass.Set("strength", 0.35)
+	pass.Set("radius", 0.35)
+	return pass
+}
+
+// NewFXAAPass smooths jagged edges. It works best last, on tone mapped
+// colour.
+func NewFXAAPass() *ShaderPass {
+	return mustShaderPass("fxaa", "fxaa_fragment_shader.glsl")
+}
+
+// BloomPass makes bright areas glow. Light above Threshold is blurred at
+// half resolution and added back scaled by Intensity.
+type BloomPass struct {
+	Threshold float32
+	Intensity float32
+
+	extract, blur, combine uint32
+	targets                [2]renderTarget
+}
+
+func NewBloomPass() *BloomPass {
+	program := func(fragment string) uint32 {
+		p, err := shader.NewProgramFromFiles("post_vertex_shader.glsl", fragment)
+		if err != nil {
+			logrus.Fatalf("failed to create bloom pass: %v", err)
+		}
+		return p
+	}
+	return &BloomPass{
+		Threshold: 0.9,
+		Intensity: 0.6,
+		extract:   program("bloom_extract_fragment_shader.glsl"),
+		blur:      program("bloom_blur_fragment_shader.glsl"),
+		combine:   program("bloom_combine_fragment_shader.glsl"),
+	}
+}
+
+func (b *BloomPass) Name() string {
+	return "bloom"
+}
+
+func (b *BloomPass) Resize(width, height int) {
+	for i := range b.targets {
+		b.targets[i].delete()
+		var err error
+		if b.targets[i], err = newRenderTarget(max(width/2, 1), max(height/2, 1), false); err != nil {
+			logrus.Errorln("bloom:", err)
+		}
+	}
+}
+
+func (b *BloomPass) Apply(input PostInput) {
+	var output int32
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &output)
+
+	half := PostInput{Width: b.targets[0].width, Height: b.targets[0].height}
+	gl.Viewport(0, 0, int32(half.Width), int32(half.Height))
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, b.targets[0].fbo)
+	gl.UseProgram(b.extract)
+	bindPostInput(b.extract, input)
+	gl.Uniform1f(gl.GetUniformLocation(b.extract, gl.Str("threshold\x00")), b.Threshold)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+
+	gl.UseProgram(b.blur)
+	directionLoc := gl.GetUniformLocation(b.blur, gl.Str("direction\x00"))
+	for i, direction := range [][2]float32{{1, 0}, {0, 1}} {
+		half.Color = b.targets[i].color
+		gl.BindFramebuffer(gl.FRAMEBUFFER, b.targets[1-i].fbo)
+		bindPostInput(b.blur, half)
+		gl.Uniform2f(directionLoc, direction[0], direction[1])
+		gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(output))
+	gl.Viewport(0, 0, int32(input.Width), int32(input.Height))
+	gl.UseProgram(b.combine)
+	bindPostInput(b.combine, input)
+	gl.ActiveTexture(gl.TEXTURE2)
+	gl.BindTexture(gl.TEXTURE_2D, b.targets[0].color)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform1i(gl.GetUniformLocation(b.combine, gl.Str("bloomTexture\x00")), 2)
+	gl.Uniform1f(gl.GetUniformLocation(b.combine, gl.Str("intensity\x00")), b.Intensity)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
+
+// DefaultPostPasses is bloom, tone mapping, a vignette and FXAA, in that
+// order.
+func DefaultPostPasses() []PostPass {
+	return []PostPass{
+		NewBloomPass(),
+		NewToneMapPass(),
+		NewVignettePass(),
+		NewFXAAPass(),
+	}
+}
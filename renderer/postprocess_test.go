@@ -0,0 +1,59 @@
+package renderer
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testPass struct {
+	name    string
+	resized [2]int
+}
+
+func (p *testPass) Name() string             { return p.name }
+func (p *testPass) Resize(width, height int) { p.resized = [2]int{width, height} }
+func (p *testPass) Apply(input PostInput)    {}
+
+func TestPostProcessorPasses(t *testing.T) {
+	p := NewPostProcessor(&testPass{name: "a"}, &testPass{name: "b"}, &testPass{name: "c"})
+	if got, want := p.Passes(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Passes = %v, want %v", got, want)
+	}
+
+	replacement := &testPass{name: "b"}
+	p.Add(replacement)
+	if got, _ := p.Pass("b"); got != replacement {
+		t.Error("Add didn't replace the pass of the same name")
+	}
+	if got, want := p.Passes(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replacing moved the pass: %v", got)
+	}
+
+	if !p.SetEnabled("a", false) || p.SetEnabled("missing", false) {
+		t.Error("SetEnabled should report whether the pass exists")
+	}
+	var enabled []string
+	for _, pass := range p.enabledPasses() {
+		enabled = append(enabled, pass.Name())
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("enabled passes = %v, want %v", enabled, want)
+	}
+
+	if !p.Remove("c") || p.Remove("c") {
+		t.Error("Remove should report whether the pass exists")
+	}
+	if got, want := p.Passes(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Passes after Remove = %v, want %v", got, want)
+	}
+}
+
+func TestPostProcessorResizesPassesAddedLater(t *testing.T) {
+	p := NewPostProcessor()
+	p.width, p.height = 640, 360
+	pass := &testPass{name: "late"}
+	p.Add(pass)
+	if pass.resized != [2]int{640, 360} {
+		t.Errorf("pass added after sizing was resized to %v", pass.resized)
+	}
+}
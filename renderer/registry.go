@@ -0,0 +1,40 @@
+package renderer
+
+import (
+	"fmt"
+	"sort"
+)
+
+type MesherFactory func() Mesher
+
+var meshers = map[string]MesherFactory{
+	"cube":           func() Mesher { return NewCubeMesher() },
+	"greedy":         func() Mesher { return NewGreedyMesher() },
+	"binary-greedy":  func() Mesher { return NewBinaryGreedyMesher() },
+	"surface-nets":   func() Mesher { return NewSurfaceNetsMesher() },
+	"texture-greedy": func() Mesher { return NewTextureGreedyMesher(0) },
+	"packed-greedy":  func() Mesher { return NewPackedMesher(NewBinaryGreedyMesher()) },
+}
+
+// RegisterMesher makes a mesher available to tools that look meshers up by
+// name, such as cmd/meshbench.
+func RegisterMesher(name string, factory MesherFactory) {
+	meshers[name] = factory
+}
+
+func MesherNames() []string {
+	names := make([]string, 0, len(meshers))
+	for name := range meshers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func NewMesher(name string) (Mesher, error) {
+	factory, ok := meshers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mesher %q", name)
+	}
+	return factory(), nil
+}
@@ -24,11 +24,33 @@ type Renderer interface {
 	SetMessageBus(m message.MessageBus)
 }
 
+// ShaderMesher is implemented by meshers whose vertex format needs a program
+// other than the coloured cube shaders. Shaders returns file names in
+// shader.ShaderFS.
+type ShaderMesher interface {
+	Shaders() (vertex, fragment string)
+}
+
+// BorderMesher is implemented by meshers that mesh one section of a larger
+// world and need the cubes around it. border holds cubes of the neighbouring
+// sections, in the same space, that touch the section; they produce no
+// geometry of their own.
+type BorderMesher interface {
+	CreateMeshWithBorder(cubes, border []primitive.Cube)
+	GenerateMeshWithBorder(cubes, border []primitive.Cube)
+}
+
 type Mesher interface {
 	CreateMesh(cubes []primitive.Cube)
+	GenerateMesh(cubes []primitive.Cube)
+	VertexLayout() VertexLayout
 	Bind()
 	Unbind()
 	Draw()
 	GetMesh() ([]float32, []uint32)
 	String() string
+	// Clone returns an empty mesher with the same configuration.
+	Clone() Mesher
+	// Delete frees the mesher's GL objects.
+	Delete()
 }
@@ -0,0 +1,320 @@
+package renderer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dfirebaugh/cube/pkg/occlusion"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// sectionSize is the edge length of a mesh section in cubes.
+const sectionSize = 16
+
+// sectionKey is a section's position in section units.
+type sectionKey [3]int
+
+func sectionOf(x, y, z float32) sectionKey {
+	return sectionKey{
+		int(math.Floor(float64(x) / sectionSize)),
+		int(math.Floor(float64(y) / sectionSize)),
+		int(math.Floor(float64(z) / sectionSize)),
+	}
+}
+
+func (k sectionKey) origin() mgl32.Vec3 {
+	return mgl32.Vec3{float32(k[0] * sectionSize), float32(k[1] * sectionSize), float32(k[2] * sectionSize)}
+}
+
+// bounds is the world space box a section's meshes can cover. It is padded
+// by a cube since some meshers centre cubes on their position.
+func (k sectionKey) bounds() primitive.AABB {
+	origin := k.origin()
+	pad := mgl32.Vec3{1, 1, 1}
+	return primitive.AABB{
+		Min: origin.Sub(pad),
+		Max: origin.Add(mgl32.Vec3{sectionSize, sectionSize, sectionSize}).Add(pad),
+	}
+}
+
+// meshSection owns the cubes of one section and the meshes built from them.
+// Meshes are built in section-local space and placed with a model matrix.
+type meshSection struct {
+	key   sectionKey
+	cubes []primitive.Cube
+	// cells finds the first cube added to each section-local cell.
+	cells map[[3]int]primitive.Cube
+
+	mesher         Mesher
+	models         *ModelMesher
+	translucent    *TranslucentMesher
+	hasModels      bool
+	hasTranslucent bool
+	visibility     occlusion.Visibility
+	vertices       int
+	triangles      int
+
+	dirty       bool
+	needsSort   bool
+	lastSortEye mgl32.Vec3
+}
+
+func newMeshSection(key sectionKey, mesher Mesher) *meshSection {
+	return &meshSection{
+		key:         key,
+		cells:       make(map[[3]int]primitive.Cube),
+		mesher:      mesher,
+		models:      NewModelMesher(),
+		translucent: NewTranslucentMesher(),
+	}
+}
+
+// model places the section's meshes in the world.
+func (s *meshSection) model() mgl32.Mat4 {
+	origin := s.key.origin()
+	return mgl32.Translate3D(origin[0], origin[1], origin[2])
+}
+
+func (s *meshSection) toLocal(cubes []primitive.Cube) []primitive.Cube {
+	return toLocal(s.key, cubes)
+}
+
+func toLocal(key sectionKey, cubes []primitive.Cube) []primitive.Cube {
+	origin := key.origin()
+	local := make([]primitive.Cube, len(cubes))
+	for i, cube := range cubes {
+		cube.X -= origin[0]
+		cube.Y -= origin[1]
+		cube.Z -= origin[2]
+		local[i] = cube
+	}
+	return local
+}
+
+// SectionCubes splits cubes into the sections MeshRenderer meshes on their
+// own, each moved into section-local space, in a fixed order. borders[i]
+// holds the cubes around sections[i], in the same space, for BorderMeshers.
+// Meshers that work on a sectionSize³ grid only handle one section at a time.
+func SectionCubes(cubes []primitive.Cube) (sections, borders [][]primitive.Cube) {
+	split := make(map[sectionKey]*meshSection)
+	for _, cube := range cubes {
+		key := sectionOf(cube.X, cube.Y, cube.Z)
+		section, ok := split[key]
+		if !ok {
+			section = &meshSection{key: key}
+			split[key] = section
+		}
+		section.cubes = append(section.cubes, cube)
+	}
+	keys := make([]sectionKey, 0, len(split))
+	for key := range split {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[2] < b[2]
+	})
+	sections = make([][]primitive.Cube, len(keys))
+	borders = make([][]primitive.Cube, len(keys))
+	for i, key := range keys {
+		section := split[key]
+		sections[i] = section.toLocal(section.cubes)
+		borders[i] = section.toLocal(section.border(split))
+	}
+	return sections, borders
+}
+
+// remesh rebuilds the section's meshes. border is the neighbouring cubes
+// that touch the section, which hide model and translucent faces on its
+// edge. When cache is set, the main mesh is loaded from it if the section
+// and its border are unchanged.
+func (s *meshSection) remesh(cache *MeshCache, border []primitive.Cube) {
+	local := s.toLocal(s.cubes)
+	localBorder := s.toLocal(border)
+	opaque, hasModels, hasTranslucent := splitPasses(local)
+	s.createMesh(opaque, cache, localBorder)
+
+	if hasModels {
+		s.models.CreateMeshWithBorder(local, localBorder)
+	} else if s.hasModels {
+		s.models.Delete()
+	}
+	s.hasModels = hasModels
+
+	if hasTranslucent {
+		s.translucent.CreateMeshWithBorder(local, localBorder)
+		s.needsSort = true
+	} else if s.hasTranslucent {
+		s.translucent.Delete()
+	}
+	s.hasTranslucent = hasTranslucent
+
+	s.visibility = computeVisibility(local)
+	s.countGeometry()
+	s.dirty = false
+}
+
+// countGeometry totals the vertices and triangles of the section's meshes
+// for Metrics.
+func (s *meshSection) countGeometry() {
+	s.vertices, s.triangles = meshSize(s.mesher)
+	for _, m := range []struct {
+		mesher Mesher
+		has    bool
+	}{{s.models, s.hasModels}, {s.translucent, s.hasTranslucent}} {
+		if !m.has {
+			continue
+		}
+		v, t := meshSize(m.mesher)
+		s.vertices += v
+		s.triangles += t
+	}
+}
+
+// computeVisibility records which faces of the section are joined through
+// cells that aren't filled by an opaque cube.
+func computeVisibility(local []primitive.Cube) occlusion.Visibility {
+	var solid [sectionSize][sectionSize][sectionSize]bool
+	for _, cube := range local {
+		if cube.ShouldHide || cube.Translucent || !cube.IsFull() {
+			continue
+		}
+		x := int(math.Floor(float64(cube.X)))
+		y := int(math.Floor(float64(cube.Y)))
+		z := int(math.Floor(float64(cube.Z)))
+		if x < 0 || y < 0 || z < 0 || x >= sectionSize || y >= sectionSize || z >= sectionSize {
+			continue
+		}
+		solid[x][y][z] = true
+	}
+	return occlusion.ComputeVisibility(sectionSize, func(x, y, z int) bool {
+		return solid[x][y][z]
+	})
+}
+
+func (s *meshSection) createMesh(cubes []primitive.Cube, cache *MeshCache, localBorder []primitive.Cube) {
+	create := func() {
+		if mesher, ok := s.mesher.(BorderMesher); ok {
+			opaqueBorder, _, _ := splitPasses(localBorder)
+			mesher.CreateMeshWithBorder(cubes, opaqueBorder)
+			return
+		}
+		s.mesher.CreateMesh(cubes)
+	}
+
+	mesher, ok := s.mesher.(CacheableMesher)
+	if cache == nil || !ok {
+		create()
+		return
+	}
+
+	key := cache.Key(mesher, cubes, localBorder)
+	if vertices, indices, ok := cache.Load(key); ok {
+		mesher.CreateMeshFromData(vertices, indices)
+		return
+	}
+
+	create()
+	vertices, indices := mesher.GetMesh()
+	if err := cache.Store(key, vertices, indices); err != nil {
+		logrus.Warnln("failed to store mesh in cache:", err)
+	}
+}
+
+// border returns the cubes of the neighbouring sections, including those
+// across its edges and corners, that touch this one.
+func (s *meshSection) border(sections map[sectionKey]*meshSection) []primitive.Cube {
+	return sectionBorder(s.key, func(key sectionKey) []primitive.Cube {
+		if neighbor, ok := sections[key]; ok {
+			return neighbor.cubes
+		}
+		return nil
+	})
+}
+
+// sectionBorder returns the cubes around the section at key, looking up
+// each neighbouring section's cubes with cubesAt.
+func sectionBorder(key sectionKey, cubesAt func(sectionKey) []primitive.Cube) []primitive.Cube {
+	lo := key.origin()
+	hi := lo.Add(mgl32.Vec3{sectionSize, sectionSize, sectionSize})
+	var border []primitive.Cube
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				if dx == 0 && dy == 0 && dz == 0 {
+					continue
+				}
+				for _, cube := range cubesAt(sectionKey{key[0] + dx, key[1] + dy, key[2] + dz}) {
+					p := [3]float32{cube.X, cube.Y, cube.Z}
+					if touches(p, lo, hi) {
+						border = append(border, cube)
+					}
+				}
+			}
+		}
+	}
+	return border
+}
+
+// touches reports whether p is within a cube of the box from lo to hi.
+func touches(p [3]float32, lo, hi mgl32.Vec3) bool {
+	for d := 0; d < 3; d++ {
+		if p[d] < lo[d]-1 || p[d] >= hi[d]+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// cell is the section-local cell of the world cell at x, y, z.
+func (s *meshSection) cell(x, y, z int) [3]int {
+	return [3]int{x - s.key[0]*sectionSize, y - s.key[1]*sectionSize, z - s.key[2]*sectionSize}
+}
+
+func (s *meshSection) add(cube primitive.Cube) {
+	s.cubes = append(s.cubes, cube)
+	cell := s.cell(int(math.Floor(float64(cube.X))), int(math.Floor(float64(cube.Y))), int(math.Floor(float64(cube.Z))))
+	if _, ok := s.cells[cell]; !ok {
+		s.cells[cell] = cube
+	}
+}
+
+// blockAt returns the first cube added to the cell at x, y, z.
+func (s *meshSection) blockAt(x, y, z int) (primitive.Cube, bool) {
+	cube, ok := s.cells[s.cell(x, y, z)]
+	return cube, ok
+}
+
+// removeAt drops every cube in the cell at x, y, z and reports whether any
+// were found.
+func (s *meshSection) removeAt(x, y, z int) bool {
+	cell := s.cell(x, y, z)
+	if _, ok := s.cells[cell]; !ok {
+		return false
+	}
+	delete(s.cells, cell)
+
+	kept := s.cubes[:0]
+	for _, cube := range s.cubes {
+		if int(math.Floor(float64(cube.X))) == x && int(math.Floor(float64(cube.Y))) == y && int(math.Floor(float64(cube.Z))) == z {
+			continue
+		}
+		kept = append(kept, cube)
+	}
+	s.cubes = kept
+	return true
+}
+
+func (s *meshSection) delete() {
+	s.mesher.Delete()
+	s.models.Delete()
+	s.translucent.Delete()
+}
@@ -0,0 +1,128 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/occlusion"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+)
+
+// floor fills the y = 0 layer of a section with cubes built by cube.
+func floor(cube func(x, z int) primitive.Cube) []primitive.Cube {
+	var cubes []primitive.Cube
+	for x := 0; x < sectionSize; x++ {
+		for z := 0; z < sectionSize; z++ {
+			cubes = append(cubes, cube(x, z))
+		}
+	}
+	return cubes
+}
+
+func TestComputeVisibilitySkipsSeeThroughCubes(t *testing.T) {
+	cube := func(x, z int) primitive.Cube {
+		c := primitive.Cube{Size: 1}
+		c.X, c.Z = float32(x), float32(z)
+		return c
+	}
+	tests := []struct {
+		name    string
+		cubes   []primitive.Cube
+		crosses bool
+	}{
+		{"opaque floor", floor(cube), false},
+		{"translucent floor", floor(func(x, z int) primitive.Cube {
+			c := cube(x, z)
+			c.Translucent = true
+			return c
+		}), true},
+		{"slab floor", floor(func(x, z int) primitive.Cube {
+			c := cube(x, z)
+			c.Model = primitive.SlabModel
+			return c
+		}), true},
+	}
+	for _, tt := range tests {
+		v := computeVisibility(tt.cubes)
+		if got := v.Connects(occlusion.NegY, occlusion.PosY); got != tt.crosses {
+			t.Errorf("%s: bottom connects to top = %v, want %v", tt.name, got, tt.crosses)
+		}
+	}
+}
+
+// A run of water crossing x = 16 has no wall where the sections meet, and a
+// slab against a full block in the next section drops the touching face.
+func TestSectionBorderHidesFaces(t *testing.T) {
+	sections := map[sectionKey]*meshSection{}
+	add := func(x int, cube primitive.Cube) {
+		cube.X, cube.Size = float32(x), 1
+		key := sectionOf(cube.X, cube.Y, cube.Z)
+		if sections[key] == nil {
+			sections[key] = newMeshSection(key, NewGreedyMesher())
+		}
+		sections[key].add(cube)
+	}
+	for x := 13; x < 19; x++ {
+		add(x, primitive.Cube{Translucent: true, Opacity: 0.5})
+	}
+
+	quads := 0
+	for _, section := range sections {
+		m := NewTranslucentMesher()
+		m.GenerateMeshWithBorder(section.toLocal(section.cubes), section.toLocal(section.border(sections)))
+		quads += len(m.quads)
+	}
+	// Four sides of six cubes and the two ends.
+	if quads != 6*4+2 {
+		t.Errorf("water run has %d quads, want %d", quads, 6*4+2)
+	}
+
+	sections = map[sectionKey]*meshSection{}
+	add(15, primitive.Cube{Model: primitive.SlabModel})
+	add(16, primitive.Cube{})
+	slab := sections[sectionOf(15, 0, 0)]
+	withBorder, without := NewModelMesher(), NewModelMesher()
+	local := slab.toLocal(slab.cubes)
+	withBorder.GenerateMeshWithBorder(local, slab.toLocal(slab.border(sections)))
+	without.GenerateMesh(local)
+	if got, all := len(withBorder.indices)/6, len(without.indices)/6; got != all-1 {
+		t.Errorf("slab against the next section has %d faces, want %d", got, all-1)
+	}
+}
+
+func TestSectionBlockAt(t *testing.T) {
+	at := func(x, y, z int, color component.Color) primitive.Cube {
+		cube := primitive.Cube{Size: 1, Color: color}
+		cube.X, cube.Y, cube.Z = float32(x), float32(y), float32(z)
+		return cube
+	}
+	red, blue := component.Color{1, 0, 0}, component.Color{0, 0, 1}
+	key := sectionOf(-3, 17, 0)
+	s := newMeshSection(key, NewGreedyMesher())
+	s.add(at(-3, 17, 0, red))
+	s.add(at(-3, 17, 0, blue))
+	s.add(at(-16, 31, 15, blue))
+
+	if cube, ok := s.blockAt(-3, 17, 0); !ok || cube.Color != red {
+		t.Errorf("blockAt(-3, 17, 0) = %v, %v, want the first cube added", cube.Color, ok)
+	}
+	if _, ok := s.blockAt(-16, 31, 15); !ok {
+		t.Error("cube in the section's far corner not found")
+	}
+	if _, ok := s.blockAt(-4, 17, 0); ok {
+		t.Error("empty cell found")
+	}
+
+	if !s.removeAt(-3, 17, 0) {
+		t.Fatal("removeAt found nothing")
+	}
+	if _, ok := s.blockAt(-3, 17, 0); ok {
+		t.Error("removed cell still found")
+	}
+	if len(s.cubes) != 1 {
+		t.Errorf("%d cubes left, want 1", len(s.cubes))
+	}
+	if s.removeAt(-3, 17, 0) {
+		t.Error("removeAt found an empty cell")
+	}
+}
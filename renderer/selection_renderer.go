@@ -0,0 +1,230 @@
+package renderer
+
+import (
+	"sync/atomic"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSelectionDistance is how far away a block can be targeted.
+const DefaultSelectionDistance = 8
+
+const (
+	// selectionInflate grows the outline so it isn't hidden by the block's
+	// own faces.
+	selectionInflate = 0.005
+	// selectionLift keeps the face tint in front of the face it covers.
+	selectionLift = 0.002
+)
+
+var positionLayout = VertexLayout{
+	Stride: 3 * 4,
+	Attributes: []VertexAttribute{
+		{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+	},
+}
+
+// SelectionRenderer outlines the block the camera is looking at and tints
+// the face it is looking at. The block comes from a voxel raycast through
+// blocks along the camera's direction. Add it after the renderers that draw
+// the world. It is toggled with the ToggleSelection bus topic.
+type SelectionRenderer struct {
+	program     uint32
+	camera      Camera
+	window      Window
+	bus         message.MessageBus
+	blocks      primitive.BlockSource
+	enabled     atomic.Bool
+	maxDistance float32
+	outline     component.Color
+	tint        mgl32.Vec4
+
+	target    primitive.VoxelHit
+	hasTarget bool
+	lines     meshBuffers
+	face      meshBuffers
+}
+
+func NewSelectionRenderer(blocks primitive.BlockSource) *SelectionRenderer {
+	program, err := shader.NewProgramFromFiles("selection_vertex_shader.glsl", "selection_fragment_shader.glsl")
+	if err != nil {
+		logrus.Fatalln("failed to create selection program:", err)
+	}
+	r := &SelectionRenderer{
+		program:     program,
+		blocks:      blocks,
+		maxDistance: DefaultSelectionDistance,
+		outline:     component.Color{0.05, 0.05, 0.05},
+		tint:        mgl32.Vec4{1, 1, 1, 0.25},
+	}
+	r.enabled.Store(true)
+	return r
+}
+
+func (r *SelectionRenderer) SetCamera(camera Camera) {
+	r.camera = camera
+}
+
+func (r *SelectionRenderer) SetWindow(window Window) {
+	r.window = window
+}
+
+func (r *SelectionRenderer) SetMessageBus(m message.MessageBus) {
+	r.bus = m
+	go r.subscribeToEvents()
+}
+
+// SetEnabled turns targeting on or off. It is on by default.
+func (r *SelectionRenderer) SetEnabled(enabled bool) {
+	r.enabled.Store(enabled)
+}
+
+func (r *SelectionRenderer) Enabled() bool {
+	return r.enabled.Load()
+}
+
+// SetMaxDistance sets how far away a block can be targeted.
+func (r *SelectionRenderer) SetMaxDistance(distance float32) {
+	r.maxDistance = distance
+}
+
+// SetColors sets the outline colour and the colour blended over the
+// targeted face.
+func (r *SelectionRenderer) SetColors(outline component.Color, tint mgl32.Vec4) {
+	r.outline = outline
+	r.tint = tint
+}
+
+// Target is the block found by the last Render, for building and
+// breaking. Call it on the render thread.
+func (r *SelectionRenderer) Target() (primitive.VoxelHit, bool) {
+	return r.target, r.hasTarget
+}
+
+func (r *SelectionRenderer) Render() {
+	r.hasTarget = false
+	if !r.enabled.Load() || r.camera == nil || r.window == nil || r.blocks == nil {
+		return
+	}
+	r.target, r.hasTarget = primitive.VoxelRaycast(r.blocks, r.camera.GetPosition(), r.camera.GetDirection(), r.maxDistance)
+	if !r.hasTarget {
+		return
+	}
+
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	cull := gl.IsEnabled(gl.CULL_FACE)
+	blend := gl.IsEnabled(gl.BLEND)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.CULL_FACE)
+
+	gl.UseProgram(r.program)
+	view := r.camera.GetViewMatrix()
+	projection := perspective(r.camera, r.window)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("view\x00")), 1, false, &view[0])
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("projection\x00")), 1, false, &projection[0])
+	colorLoc := gl.GetUniformLocation(r.program, gl.Str("color\x00"))
+
+	if r.target.Normal != (mgl32.Vec3{}) {
+		quad := faceQuad(r.target.Box, r.target.Normal, selectionLift)
+		vertices := make([]float32, 0, 6*3)
+		for _, i := range []int{0, 1, 2, 0, 2, 3} {
+			vertices = append(vertices, quad[i][0], quad[i][1], quad[i][2])
+		}
+		gl.Enable(gl.BLEND)
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+		gl.DepthMask(false)
+		gl.Uniform4f(colorLoc, r.tint[0], r.tint[1], r.tint[2], r.tint[3])
+		r.face.upload(vertices, positionLayout)
+		gl.BindVertexArray(r.face.vao)
+		gl.DrawArrays(gl.TRIANGLES, 0, 6)
+		gl.DepthMask(true)
+		if !blend {
+			gl.Disable(gl.BLEND)
+		}
+	}
+
+	vertices := outlineVertices(r.target.Cube.Boxes(), selectionInflate)
+	gl.Uniform4f(colorLoc, r.outline[0], r.outline[1], r.outline[2], 1)
+	r.lines.upload(vertices, positionLayout)
+	gl.BindVertexArray(r.lines.vao)
+	gl.DrawArrays(gl.LINES, 0, int32(len(vertices)/3))
+	gl.BindVertexArray(0)
+
+	if cull {
+		gl.Enable(gl.CULL_FACE)
+	}
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	checkGLError("SelectionRenderer")
+}
+
+// outlineVertices returns the edges of boxes, each grown by inflate on
+// every side, as pairs of positions for gl.LINES.
+func outlineVertices(boxes []primitive.AABB, inflate float32) []float32 {
+	grow := mgl32.Vec3{inflate, inflate, inflate}
+	vertices := make([]float32, 0, len(boxes)*12*2*3)
+	for _, box := range boxes {
+		box = primitive.AABB{Min: box.Min.Sub(grow), Max: box.Max.Add(grow)}
+		for _, edge := range boxEdges(box) {
+			vertices = append(vertices,
+				edge[0][0], edge[0][1], edge[0][2],
+				edge[1][0], edge[1][1], edge[1][2],
+			)
+		}
+	}
+	return vertices
+}
+
+// faceQuad returns the corners, in order around the edge, of the face of
+// box that normal points out of, moved lift along the normal.
+func faceQuad(box primitive.AABB, normal mgl32.Vec3, lift float32) [4]mgl32.Vec3 {
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if mgl32.Abs(normal[i]) > mgl32.Abs(normal[axis]) {
+			axis = i
+		}
+	}
+	u, v := (axis+1)%3, (axis+2)%3
+
+	var corner mgl32.Vec3
+	if normal[axis] > 0 {
+		corner[axis] = box.Max[axis] + lift
+	} else {
+		corner[axis] = box.Min[axis] - lift
+	}
+	var quad [4]mgl32.Vec3
+	for i, c := range [4][2]bool{{false, false}, {true, false}, {true, true}, {false, true}} {
+		quad[i] = corner
+		quad[i][u] = box.Min[u]
+		if c[0] {
+			quad[i][u] = box.Max[u]
+		}
+		quad[i][v] = box.Min[v]
+		if c[1] {
+			quad[i][v] = box.Max[v]
+		}
+	}
+	return quad
+}
+
+func (r *SelectionRenderer) subscribeToEvents() {
+	if r.bus == nil {
+		logrus.Println("MessageBus not set for SelectionRenderer")
+		return
+	}
+
+	msg := r.bus.Subscribe()
+	defer r.bus.Unsubscribe(msg)
+
+	for m := range msg {
+		if m.GetTopic() == "ToggleSelection" {
+			r.enabled.Store(!r.enabled.Load())
+		}
+	}
+}
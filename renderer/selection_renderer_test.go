@@ -0,0 +1,68 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFaceQuad(t *testing.T) {
+	box := primitive.AABB{Min: mgl32.Vec3{1, 2, 3}, Max: mgl32.Vec3{2, 2.5, 4}}
+	tests := []struct {
+		normal mgl32.Vec3
+		axis   int
+		want   float32
+	}{
+		{mgl32.Vec3{0, 1, 0}, 1, 2.6},
+		{mgl32.Vec3{0, -1, 0}, 1, 1.9},
+		{mgl32.Vec3{1, 0, 0}, 0, 2.1},
+		{mgl32.Vec3{0, 0, -1}, 2, 2.9},
+	}
+	for _, tt := range tests {
+		quad := faceQuad(box, tt.normal, 0.1)
+		var sum mgl32.Vec3
+		for _, corner := range quad {
+			if mgl32.Abs(corner[tt.axis]-tt.want) > 1e-5 {
+				t.Errorf("normal %v: corner %v not on the face", tt.normal, corner)
+			}
+			sum = sum.Add(corner)
+		}
+		centre := box.Min.Add(box.Max).Mul(0.5)
+		centre[tt.axis] = tt.want
+		if !sum.Mul(0.25).ApproxEqualThreshold(centre, 1e-5) {
+			t.Errorf("normal %v: quad centred on %v, want %v", tt.normal, sum.Mul(0.25), centre)
+		}
+		// Consecutive corners share an edge of the face.
+		for i := range quad {
+			d := quad[i].Sub(quad[(i+1)%4])
+			moved := 0
+			for _, c := range d {
+				if c != 0 {
+					moved++
+				}
+			}
+			if moved != 1 {
+				t.Errorf("normal %v: corners %v and %v aren't along an edge", tt.normal, quad[i], quad[(i+1)%4])
+			}
+		}
+	}
+}
+
+func TestOutlineVertices(t *testing.T) {
+	boxes := primitive.StairsModel(primitive.North).Boxes
+	vertices := outlineVertices(boxes, 0.01)
+	if got, want := len(vertices), len(boxes)*12*2*3; got != want {
+		t.Fatalf("got %d floats, want %d", got, want)
+	}
+	for i := 0; i < len(vertices); i += 3 {
+		for axis := 0; axis < 3; axis++ {
+			if v := vertices[i+axis]; v < -0.01-1e-6 || v > 1.01+1e-6 {
+				t.Fatalf("vertex %v outside the inflated cell", vertices[i:i+3])
+			}
+		}
+	}
+	if vertices[0] != -0.01 {
+		t.Errorf("first corner at %v, want the box grown by 0.01", vertices[0:3])
+	}
+}
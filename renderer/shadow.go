@@ -0,0 +1,223 @@
+package renderer
+
+import (
+	"math"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// shadowTextureUnit is kept free of other textures so the shadow map can be
+// bound alongside block textures and texture arrays.
+const shadowTextureUnit = 1
+
+// ShadowSettings configures the sun's shadow map.
+type ShadowSettings struct {
+	Enabled bool
+	// Size is the width and height of the depth texture.
+	Size int
+	// Distance is how far from the camera shadows are drawn. Shorter
+	// distances give sharper shadows.
+	Distance float32
+	// Bias pushes depth comparisons away from the surface to avoid acne.
+	Bias float32
+	// PCF is the radius in texels of the filter that softens shadow edges.
+	// Zero takes a single sample.
+	PCF int
+}
+
+func DefaultShadowSettings() ShadowSettings {
+	return ShadowSettings{
+		Enabled:  true,
+		Size:     2048,
+		Distance: 48,
+		Bias:     0.002,
+		PCF:      1,
+	}
+}
+
+// shadowPass renders depth from the sun into a texture that the lit shaders
+// sample. The light's orthographic frustum is fitted around the part of the
+// camera's view within Distance.
+type shadowPass struct {
+	settings   ShadowSettings
+	fbo        uint32
+	texture    uint32
+	size       int
+	program    uint32
+	packed     uint32
+	lightSpace mgl32.Mat4
+}
+
+// setSettings applies new settings. GL objects are made on the next render,
+// so it is safe to call from any thread that owns the renderer.
+func (s *shadowPass) setSettings(settings ShadowSettings) {
+	defaults := DefaultShadowSettings()
+	if settings.Size <= 0 {
+		settings.Size = defaults.Size
+	}
+	if settings.Distance <= 0 {
+		settings.Distance = defaults.Distance
+	}
+	s.settings = settings
+}
+
+// toggle turns shadows on or off, starting from the defaults if they were
+// never configured.
+func (s *shadowPass) toggle() {
+	if s.settings.Size == 0 {
+		s.setSettings(DefaultShadowSettings())
+		return
+	}
+	s.settings.Enabled = !s.settings.Enabled
+}
+
+func (s *shadowPass) enabled() bool {
+	return s.settings.Enabled
+}
+
+func (s *shadowPass) setup() {
+	if s.program == 0 {
+		var err error
+		s.program, err = shader.NewProgramFromFiles("shadow_vertex_shader.glsl", "shadow_fragment_shader.glsl")
+		if err != nil {
+			logrus.Fatalln("failed to create shadow program:", err)
+		}
+		s.packed, err = shader.NewProgramFromFiles("shadow_packed_vertex_shader.glsl", "shadow_fragment_shader.glsl")
+		if err != nil {
+			logrus.Fatalln("failed to create shadow program:", err)
+		}
+	}
+	if s.texture != 0 && s.size == s.settings.Size {
+		return
+	}
+	s.deleteTarget()
+
+	s.size = s.settings.Size
+	gl.GenTextures(1, &s.texture)
+	gl.BindTexture(gl.TEXTURE_2D, s.texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT24, int32(s.size), int32(s.size), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
+	border := [4]float32{1, 1, 1, 1}
+	gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, &border[0])
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.GenFramebuffers(1, &s.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, s.texture, 0)
+	gl.DrawBuffer(gl.NONE)
+	gl.ReadBuffer(gl.NONE)
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		logrus.Errorf("shadow framebuffer incomplete: 0x%x", status)
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// render fits the light to the camera and calls draw for the casters inside
+// the light's frustum. draw is given the depth program to use and must set
+// its model uniform. The caller's framebuffer and viewport are restored.
+func (s *shadowPass) render(camera Camera, window Window, sun mgl32.Vec3, draw func(program, packed uint32, frustum primitive.Frustum)) {
+	s.setup()
+	s.lightSpace = fitLightSpace(camera, window, sun, s.settings.Distance, s.size)
+
+	var previous int32
+	var viewport [4]int32
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &previous)
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.fbo)
+	gl.Viewport(0, 0, int32(s.size), int32(s.size))
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+
+	for _, program := range []uint32{s.program, s.packed} {
+		gl.UseProgram(program)
+		gl.UniformMatrix4fv(gl.GetUniformLocation(program, gl.Str("lightSpace\x00")), 1, false, &s.lightSpace[0])
+	}
+	draw(s.program, s.packed, primitive.NewFrustum(s.lightSpace))
+	checkGLError("ShadowPass")
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+}
+
+// bind points a lit program at the shadow map. The program must be in use
+// and have had its lighting uniforms set.
+func (s *shadowPass) bind(program uint32) {
+	if !s.settings.Enabled || s.texture == 0 {
+		return
+	}
+	gl.ActiveTexture(gl.TEXTURE0 + shadowTextureUnit)
+	gl.BindTexture(gl.TEXTURE_2D, s.texture)
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("shadowsEnabled\x00")), 1)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(program, gl.Str("lightSpace\x00")), 1, false, &s.lightSpace[0])
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("shadowBias\x00")), s.settings.Bias)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("shadowPCF\x00")), int32(s.settings.PCF))
+}
+
+// renderBatch draws a single texture batch as the only caster.
+func (s *shadowPass) renderBatch(camera Camera, window Window, sun mgl32.Vec3, batch *textureBatch, model mgl32.Mat4) {
+	s.render(camera, window, sun, func(program, _ uint32, _ primitive.Frustum) {
+		gl.UseProgram(program)
+		gl.UniformMatrix4fv(gl.GetUniformLocation(program, gl.Str("model\x00")), 1, false, &model[0])
+		batch.draw()
+	})
+}
+
+func (s *shadowPass) deleteTarget() {
+	if s.fbo != 0 {
+		gl.DeleteFramebuffers(1, &s.fbo)
+	}
+	if s.texture != 0 {
+		gl.DeleteTextures(1, &s.texture)
+	}
+	s.fbo, s.texture = 0, 0
+}
+
+// shadowCasterMargin extends the light's frustum towards the sun so casters
+// outside the camera's view still shade what it sees.
+const shadowCasterMargin = 32
+
+// fitLightSpace returns an orthographic view-projection looking along sun
+// that covers a sphere around the camera's view up to distance. The sphere
+// keeps the size constant as the camera turns, and its centre is snapped to
+// whole texels so shadow edges don't shimmer as the camera moves.
+func fitLightSpace(camera Camera, window Window, sun mgl32.Vec3, distance float32, size int) mgl32.Mat4 {
+	width, height := window.GetSize()
+	aspect := float32(width) / float32(height)
+	tanHalf := float32(math.Tan(float64(mgl32.DegToRad(45)) / 2))
+
+	// A sphere through the far corners of the frustum slice, centred
+	// halfway along it.
+	halfHeight := distance * tanHalf
+	halfWidth := halfHeight * aspect
+	radius := mgl32.Vec3{halfWidth, halfHeight, distance / 2}.Len()
+	center := camera.GetPosition().Add(camera.GetDirection().Normalize().Mul(distance / 2))
+
+	dir := sun.Normalize()
+	up := mgl32.Vec3{0, 1, 0}
+	if math.Abs(float64(dir.Dot(up))) > 0.99 {
+		up = mgl32.Vec3{0, 0, 1}
+	}
+	rotation := mgl32.LookAtV(mgl32.Vec3{}, dir, up)
+
+	// Snap the centre to the texel grid in light space.
+	texel := 2 * radius / float32(size)
+	local := rotation.Mul4x1(center.Vec4(1))
+	local[0] = float32(math.Floor(float64(local[0]/texel))) * texel
+	local[1] = float32(math.Floor(float64(local[1]/texel))) * texel
+	center = rotation.Inv().Mul4x1(local).Vec3()
+
+	eye := center.Sub(dir.Mul(radius + shadowCasterMargin))
+	view := mgl32.LookAtV(eye, center, up)
+	projection := mgl32.Ortho(-radius, radius, -radius, radius, 0, 2*radius+shadowCasterMargin)
+	return projection.Mul4(view)
+}
@@ -0,0 +1,89 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testWindow struct{ width, height int }
+
+func (w testWindow) GetSize() (int, int) { return w.width, w.height }
+
+func TestFitLightSpaceCoversView(t *testing.T) {
+	camera := testCamera{eye: mgl32.Vec3{10, 20, 30}, target: mgl32.Vec3{40, 0, -10}}
+	window := testWindow{800, 600}
+	const distance = 48
+	lightSpace := fitLightSpace(camera, window, mgl32.Vec3{-0.4, -1, -0.6}, distance, 2048)
+
+	// Corners of the camera's view at the near plane and at the furthest
+	// depth whose corners are still within distance.
+	tanHalf := float32(math.Tan(float64(mgl32.DegToRad(45)) / 2))
+	aspect := float32(800) / 600
+	far := distance / mgl32.Vec3{tanHalf * aspect, tanHalf, 1}.Len()
+	toWorld := camera.GetViewMatrix().Inv()
+	for _, depth := range []float32{0.1, far} {
+		for _, x := range []float32{-1, 1} {
+			for _, y := range []float32{-1, 1} {
+				view := mgl32.Vec4{x * depth * tanHalf * aspect, y * depth * tanHalf, -depth, 1}
+				world := toWorld.Mul4x1(view)
+				clip := lightSpace.Mul4x1(world)
+				for i := 0; i < 3; i++ {
+					if clip[i] < -1 || clip[i] > 1 {
+						t.Errorf("corner %v maps to %v, outside the shadow map", world.Vec3(), clip.Vec3())
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFitLightSpaceIsStableUnderSmallMoves(t *testing.T) {
+	window := testWindow{800, 600}
+	sun := mgl32.Vec3{-0.4, -1, -0.6}
+	fit := func(eye mgl32.Vec3) mgl32.Mat4 {
+		return fitLightSpace(testCamera{eye: eye, target: eye.Add(mgl32.Vec3{0, 0, -1})}, window, sun, 48, 2048)
+	}
+	a := fit(mgl32.Vec3{0, 10, 0})
+
+	// The fit is snapped to the texel grid, so however the camera moves a
+	// fixed point lands a whole number of texels from where it was. A texel
+	// here is about 0.042 units, so each move is several texels and a
+	// fraction.
+	point := mgl32.Vec4{3, 2, -12, 1}
+	texel := float32(2) / 2048
+	for _, move := range []mgl32.Vec3{{0.3, 0, 0}, {0, 0.23, 0}, {0.17, 0.05, -0.41}} {
+		b := fit(mgl32.Vec3{0, 10, 0}.Add(move))
+		pa, pb := a.Mul4x1(point), b.Mul4x1(point)
+		moved := false
+		for i := 0; i < 2; i++ {
+			texels := float64((pb[i] - pa[i]) / texel)
+			if frac := math.Abs(texels - math.Round(texels)); frac > 0.01 {
+				t.Errorf("move %v: axis %d shifted %.3f texels, want a whole number", move, i, texels)
+			}
+			if math.Round(texels) != 0 {
+				moved = true
+			}
+		}
+		if !moved {
+			t.Errorf("move %v: the fit didn't follow the camera", move)
+		}
+	}
+}
+
+func TestShadowToggleStartsFromDefaults(t *testing.T) {
+	var s shadowPass
+	if s.enabled() {
+		t.Fatal("shadows should start disabled")
+	}
+	s.toggle()
+	if !s.enabled() || s.settings != DefaultShadowSettings() {
+		t.Fatalf("toggle from zero settings = %+v, want defaults", s.settings)
+	}
+	s.toggle()
+	if s.enabled() {
+		t.Fatal("second toggle should disable shadows")
+	}
+}
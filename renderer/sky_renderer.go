@@ -0,0 +1,197 @@
+package renderer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// Sky is a vertical gradient from Horizon, straight out, to Zenith,
+// straight up. Below the horizon stays Horizon.
+type Sky struct {
+	Horizon component.Color
+	Zenith  component.Color
+	// Sun points towards the sun, which is drawn as a disc with the moon
+	// opposite it. Leave it zero to draw neither.
+	Sun mgl32.Vec3
+	// Stars is how visible the stars and moon are, from 0 to 1.
+	Stars float32
+}
+
+func DefaultSky() Sky {
+	return Sky{
+		Horizon: component.Color{0.72, 0.82, 0.9},
+		Zenith:  component.Color{0.28, 0.48, 0.82},
+	}
+}
+
+// FlatSky is a sky of a single colour.
+func FlatSky(c component.Color) Sky {
+	return Sky{Horizon: c, Zenith: c}
+}
+
+// flat reports whether the sky is one colour everywhere.
+func (s Sky) flat() bool {
+	return s.Horizon == s.Zenith && s.Stars == 0 && s.Sun == mgl32.Vec3{}
+}
+
+var (
+	sunDiscColor  = mgl32.Vec3{1, 0.95, 0.8}
+	moonDiscColor = mgl32.Vec3{0.85, 0.88, 0.95}
+)
+
+// At mirrors sky_fragment_shader.glsl. It returns the sky's colour looking
+// along direction. Stars come from a hash that rounds differently on the
+// GPU, so they land in different places.
+func (s Sky) At(direction mgl32.Vec3) mgl32.Vec3 {
+	if direction.Len() == 0 {
+		return mgl32.Vec3(s.Horizon)
+	}
+	d := direction.Normalize()
+	t := float32(math.Sqrt(math.Max(float64(d[1]), 0)))
+	horizon, zenith := mgl32.Vec3(s.Horizon), mgl32.Vec3(s.Zenith)
+	c := horizon.Mul(1 - t).Add(zenith.Mul(t))
+
+	if s.Stars > 0 && d[1] > 0 {
+		c = c.Add(mgl32.Vec3{1, 1, 1}.Mul(starField(d) * s.Stars))
+	}
+	if s.Sun.Len() > 0 {
+		sun := d.Dot(s.Sun.Normalize())
+		c = mixVec3(c, sunDiscColor, smoothstep(0.9985, 0.999, sun))
+		c = mixVec3(c, moonDiscColor, smoothstep(0.9993, 0.9996, -sun)*s.Stars)
+	}
+	return c
+}
+
+// starField lights about one in 300 cells of a grid over the sky.
+func starField(d mgl32.Vec3) float32 {
+	cell := mgl32.Vec3{
+		float32(math.Floor(float64(d[0] * 150))),
+		float32(math.Floor(float64(d[1] * 150))),
+		float32(math.Floor(float64(d[2] * 150))),
+	}
+	_, h := math.Modf(math.Sin(float64(cell.Dot(mgl32.Vec3{12.9898, 78.233, 37.719}))) * 43758.5453)
+	if math.Abs(h) < 0.997 {
+		return 0
+	}
+	return 1
+}
+
+func mixVec3(a, b mgl32.Vec3, f float32) mgl32.Vec3 {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+func smoothstep(edge0, edge1, x float32) float32 {
+	t := (x - edge0) / (edge1 - edge0)
+	t = float32(math.Min(math.Max(float64(t), 0), 1))
+	return t * t * (3 - 2*t)
+}
+
+// SkyRenderer fills the screen with the sky: its gradient, the sun and
+// moon, and stars. Draw it before the world; it writes no depth. Its Sky
+// follows SetSky messages.
+type SkyRenderer struct {
+	camera  Camera
+	window  Window
+	bus     message.MessageBus
+	program uint32
+	vao     uint32
+
+	mu  sync.Mutex
+	sky Sky
+}
+
+func NewSkyRenderer() *SkyRenderer {
+	program, err := shader.NewProgramFromFiles("sky_vertex_shader.glsl", "sky_fragment_shader.glsl")
+	if err != nil {
+		logrus.Fatalln("failed to create sky program:", err)
+	}
+
+	r := &SkyRenderer{program: program, sky: DefaultSky()}
+	// Core profile needs a vertex array bound even with no attributes.
+	gl.GenVertexArrays(1, &r.vao)
+	return r
+}
+
+func (r *SkyRenderer) SetCamera(camera Camera) {
+	r.camera = camera
+}
+
+func (r *SkyRenderer) SetWindow(window Window) {
+	r.window = window
+}
+
+func (r *SkyRenderer) SetMessageBus(bus message.MessageBus) {
+	r.bus = bus
+	go r.subscribeToEvents()
+}
+
+func (r *SkyRenderer) SetSky(sky Sky) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sky = sky
+}
+
+func (r *SkyRenderer) Sky() Sky {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sky
+}
+
+func (r *SkyRenderer) Render() {
+	sky := r.Sky()
+	horizon, zenith := mgl32.Vec3(sky.Horizon), mgl32.Vec3(sky.Zenith)
+
+	// Drop the view's translation so only the camera's rotation matters.
+	view := r.camera.GetViewMatrix().Mat3().Mat4()
+	inverse := perspective(r.camera, r.window).Mul4(view).Inv()
+
+	gl.UseProgram(r.program)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(r.program, gl.Str("inverseViewProjection\x00")), 1, false, &inverse[0])
+	gl.Uniform3fv(gl.GetUniformLocation(r.program, gl.Str("horizonColor\x00")), 1, &horizon[0])
+	gl.Uniform3fv(gl.GetUniformLocation(r.program, gl.Str("zenithColor\x00")), 1, &zenith[0])
+	gl.Uniform3fv(gl.GetUniformLocation(r.program, gl.Str("sunDirection\x00")), 1, &sky.Sun[0])
+	gl.Uniform1f(gl.GetUniformLocation(r.program, gl.Str("stars\x00")), sky.Stars)
+
+	// Wireframe is left to the renderers that set it.
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.DepthMask(false)
+	gl.BindVertexArray(r.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.BindVertexArray(0)
+	gl.DepthMask(true)
+	gl.Enable(gl.DEPTH_TEST)
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	checkGLError("SkyRenderer")
+}
+
+func (r *SkyRenderer) subscribeToEvents() {
+	if r.bus == nil {
+		logrus.Println("MessageBus not set for SkyRenderer")
+		return
+	}
+
+	msg := r.bus.Subscribe()
+	defer r.bus.Unsubscribe(msg)
+
+	for m := range msg {
+		if m.GetTopic() != "SetSky" {
+			continue
+		}
+		sky, ok := m.GetPayload().(Sky)
+		if !ok {
+			logrus.Warnf("ignoring SetSky with payload %T", m.GetPayload())
+			continue
+		}
+		r.SetSky(sky)
+	}
+}
@@ -0,0 +1,413 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/raster"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+// SoftwareRenderer draws the same meshes as MeshRenderer with the CPU
+// rasterizer in pkg/raster, so scenes can be rendered without a GPU. Meshes
+// are built with GenerateMesh and never touch GL.
+type SoftwareRenderer struct {
+	camera Camera
+	window Window
+	bus    message.MessageBus
+	mesher Mesher
+	raster *raster.Rasterizer
+
+	sections map[sectionKey]*softwareSection
+	textures []*raster.Texture
+	stats    CullStats
+	metrics  Metrics
+
+	skyMu sync.Mutex
+	sky   Sky
+	sceneState
+}
+
+// softwareSection holds a section's cubes and its meshes decoded from their
+// vertex layouts.
+type softwareSection struct {
+	key         sectionKey
+	cubes       []primitive.Cube
+	dirty       bool
+	mesher      Mesher
+	translucent *TranslucentMesher
+
+	opaque           softwareMesh
+	models           softwareMesh
+	translucentVerts []raster.Vertex
+}
+
+type softwareMesh struct {
+	vertices []raster.Vertex
+	indices  []uint32
+}
+
+// NewSoftwareRenderer builds each section with a clone of mesher and draws
+// into a width by height image. A window set with SetWindow overrides the
+// size.
+func NewSoftwareRenderer(mesher Mesher, width, height int) *SoftwareRenderer {
+	return &SoftwareRenderer{
+		mesher:     mesher,
+		raster:     raster.NewRasterizer(width, height),
+		sections:   make(map[sectionKey]*softwareSection),
+		sky:        DefaultSky(),
+		sceneState: newSceneState(),
+	}
+}
+
+func (r *SoftwareRenderer) SetCamera(camera Camera) {
+	r.camera = camera
+}
+
+func (r *SoftwareRenderer) SetWindow(window Window) {
+	r.window = window
+}
+
+func (r *SoftwareRenderer) SetMessageBus(m message.MessageBus) {
+	r.bus = m
+	go r.subscribeToEvents()
+}
+
+// GetSize makes the renderer its own Window when none is set.
+func (r *SoftwareRenderer) GetSize() (int, int) {
+	return r.raster.Width(), r.raster.Height()
+}
+
+func (r *SoftwareRenderer) AddCube(cube primitive.Cube) {
+	key := sectionOf(cube.X, cube.Y, cube.Z)
+	section, ok := r.sections[key]
+	if !ok {
+		section = &softwareSection{key: key, mesher: r.mesher.Clone(), translucent: NewTranslucentMesher()}
+		r.sections[key] = section
+	}
+	section.cubes = append(section.cubes, cube)
+	section.dirty = true
+	x, y, z := int(math.Floor(float64(cube.X))), int(math.Floor(float64(cube.Y))), int(math.Floor(float64(cube.Z)))
+	for _, key := range borderNeighbors(key, x, y, z) {
+		if neighbor, ok := r.sections[key]; ok {
+			neighbor.dirty = true
+		}
+	}
+}
+
+// SetTextures gives the images of a texture array, indexed by layer, for
+// meshers that output a "layer" attribute.
+func (r *SoftwareRenderer) SetTextures(layers []image.Image) {
+	r.textures = r.textures[:0]
+	for _, img := range layers {
+		r.textures = append(r.textures, raster.NewTexture(img))
+	}
+}
+
+// SetClearColor replaces the sky with a single opaque colour.
+func (r *SoftwareRenderer) SetClearColor(c color.RGBA) {
+	r.SetSky(FlatSky(component.Color{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255}))
+}
+
+// SetSky sets the gradient drawn behind the scene and fades fog into its
+// horizon, as a SetSky message does for the GL renderers.
+func (r *SoftwareRenderer) SetSky(sky Sky) {
+	r.skyMu.Lock()
+	r.sky = sky
+	r.skyMu.Unlock()
+
+	fog := r.Fog()
+	fog.Color = sky.Horizon
+	r.SetFog(fog)
+}
+
+func (r *SoftwareRenderer) Sky() Sky {
+	r.skyMu.Lock()
+	defer r.skyMu.Unlock()
+	return r.sky
+}
+
+// Image is the last rendered frame. It is reused by the next Render.
+func (r *SoftwareRenderer) Image() *image.RGBA {
+	return r.raster.Image
+}
+
+// CullStats reports how many sections the last frame drew and skipped.
+func (r *SoftwareRenderer) CullStats() CullStats {
+	return r.stats
+}
+
+// Metrics reports the geometry of the sections drawn last frame.
+func (r *SoftwareRenderer) Metrics() Metrics {
+	return r.metrics
+}
+
+func (r *SoftwareRenderer) Render() {
+	window := r.window
+	if window == nil {
+		window = r
+	}
+	r.raster.Resize(window.GetSize())
+	r.clearSky(window)
+
+	viewProjection := perspective(r.camera, window).Mul4(r.camera.GetViewMatrix())
+	frustum := primitive.NewFrustum(viewProjection)
+	eye := r.camera.GetPosition()
+
+	var visible []*softwareSection
+	for _, section := range r.sections {
+		if section.dirty {
+			section.remesh(r.border(section.key))
+		}
+		if frustum.IntersectsAABB(section.key.bounds()) {
+			visible = append(visible, section)
+		}
+	}
+	r.stats = CullStats{Visible: len(visible), Culled: len(r.sections) - len(visible)}
+	r.metrics = Metrics{Mesher: r.mesher.String(), Sections: len(r.sections), Cull: r.stats}
+	for _, section := range visible {
+		for _, mesh := range []softwareMesh{section.opaque, section.models} {
+			r.metrics.Vertices += len(mesh.vertices)
+			r.metrics.Triangles += len(mesh.indices) / 3
+		}
+		// Translucent quads are four vertices and two triangles.
+		r.metrics.Vertices += len(section.translucentVerts)
+		r.metrics.Triangles += len(section.translucentVerts) / 2
+	}
+
+	lighting := r.Lighting()
+	fog := r.Fog()
+	r.raster.Fragment = func(v raster.Varyings) mgl32.Vec4 {
+		c := r.shade(v, lighting)
+		amount := fog.Amount(v.World.Sub(eye).Len())
+		rgb := c.Vec3().Mul(1 - amount).Add(mgl32.Vec3(fog.Color).Mul(amount))
+		return rgb.Vec4(c[3])
+	}
+	r.raster.CullBack = true
+	r.raster.DepthWrite = true
+	for _, section := range visible {
+		r.draw(section.opaque, section.model(), viewProjection)
+	}
+
+	// Models include cross plants, which are drawn double sided.
+	r.raster.CullBack = false
+	for _, section := range visible {
+		r.draw(section.models, section.model(), viewProjection)
+	}
+
+	r.raster.DepthWrite = false
+	sort.Slice(visible, func(i, j int) bool {
+		return sectionDistance(visible[i].key, eye) > sectionDistance(visible[j].key, eye)
+	})
+	for _, section := range visible {
+		if len(section.translucentVerts) == 0 {
+			continue
+		}
+		section.translucent.Sort(eye.Sub(section.key.origin()))
+		_, indices := section.translucent.GetMesh()
+		r.draw(softwareMesh{section.translucentVerts, indices}, section.model(), viewProjection)
+	}
+}
+
+// clearSky mirrors SkyRenderer, filling the image with the sky gradient.
+func (r *SoftwareRenderer) clearSky(window Window) {
+	sky := r.Sky()
+	if sky.flat() {
+		r.raster.Clear(toRGBA(mgl32.Vec3(sky.Horizon)))
+		return
+	}
+	r.raster.ClearDepth()
+
+	inverse := perspective(r.camera, window).Mul4(r.camera.GetViewMatrix().Mat3().Mat4()).Inv()
+	width, height := r.raster.Width(), r.raster.Height()
+	for y := 0; y < height; y++ {
+		ndcY := 1 - (float32(y)+0.5)/float32(height)*2
+		for x := 0; x < width; x++ {
+			ndcX := (float32(x)+0.5)/float32(width)*2 - 1
+			far := inverse.Mul4x1(mgl32.Vec4{ndcX, ndcY, 1, 1})
+			r.raster.Image.SetRGBA(x, y, toRGBA(sky.At(far.Vec3().Mul(1/far[3]))))
+		}
+	}
+}
+
+func toRGBA(c mgl32.Vec3) color.RGBA {
+	channel := func(v float32) uint8 {
+		return uint8(math.Min(math.Max(float64(v), 0), 1)*255 + 0.5)
+	}
+	return color.RGBA{channel(c[0]), channel(c[1]), channel(c[2]), 255}
+}
+
+func (r *SoftwareRenderer) draw(mesh softwareMesh, model, viewProjection mgl32.Mat4) {
+	if len(mesh.indices) == 0 {
+		return
+	}
+	transformed := make([]raster.Vertex, len(mesh.vertices))
+	for i, v := range mesh.vertices {
+		world := model.Mul4x1(v.Position)
+		v.World = world.Vec3()
+		v.Position = viewProjection.Mul4x1(world)
+		transformed[i] = v
+	}
+	r.raster.DrawIndexed(transformed, mesh.indices)
+}
+
+// shade mirrors the cube and texture array fragment shaders. Textured
+// vertices carry a layer of at least zero.
+func (r *SoftwareRenderer) shade(v raster.Varyings, lighting Lighting) mgl32.Vec4 {
+	light := lighting.Light(v.Normal, v.World)
+	if v.Layer >= 0 {
+		if v.Layer >= len(r.textures) {
+			return mgl32.Vec4{1, 0, 1, 1}
+		}
+		c := r.textures[v.Layer].Sample(v.UV)
+		return mgl32.Vec4{c[0] * light[0], c[1] * light[1], c[2] * light[2], c[3]}
+	}
+	return mgl32.Vec4{v.Color[0] * light[0], v.Color[1] * light[1], v.Color[2] * light[2], v.Color[3]}
+}
+
+func (r *SoftwareRenderer) subscribeToEvents() {
+	if r.bus == nil {
+		logrus.Println("MessageBus not set for SoftwareRenderer")
+		return
+	}
+
+	msg := r.bus.Subscribe()
+	defer r.bus.Unsubscribe(msg)
+
+	for m := range msg {
+		if m.GetTopic() == "SetSky" {
+			if sky, ok := m.GetPayload().(Sky); ok {
+				r.SetSky(sky)
+			} else {
+				logrus.Warnf("ignoring SetSky with payload %T", m.GetPayload())
+			}
+			continue
+		}
+		r.handleSceneMessage(m)
+	}
+}
+
+func (s *softwareSection) model() mgl32.Mat4 {
+	origin := s.key.origin()
+	return mgl32.Translate3D(origin[0], origin[1], origin[2])
+}
+
+// border returns the cubes around the section at key, like
+// meshSection.border.
+func (r *SoftwareRenderer) border(key sectionKey) []primitive.Cube {
+	return sectionBorder(key, func(key sectionKey) []primitive.Cube {
+		if neighbor, ok := r.sections[key]; ok {
+			return neighbor.cubes
+		}
+		return nil
+	})
+}
+
+// remesh mirrors meshSection.remesh without GL. border is the neighbouring
+// cubes that touch the section.
+func (s *softwareSection) remesh(border []primitive.Cube) {
+	local := toLocal(s.key, s.cubes)
+	localBorder := toLocal(s.key, border)
+	opaque, hasModels, hasTranslucent := splitPasses(local)
+
+	if mesher, ok := s.mesher.(BorderMesher); ok {
+		opaqueBorder, _, _ := splitPasses(localBorder)
+		mesher.GenerateMeshWithBorder(opaque, opaqueBorder)
+	} else {
+		s.mesher.GenerateMesh(opaque)
+	}
+	s.opaque = decodeMesh(s.mesher)
+
+	s.models = softwareMesh{}
+	if hasModels {
+		models := NewModelMesher()
+		models.GenerateMeshWithBorder(local, localBorder)
+		s.models = decodeMesh(models)
+	}
+
+	s.translucentVerts = nil
+	if hasTranslucent {
+		s.translucent.GenerateMeshWithBorder(local, localBorder)
+		s.translucentVerts = decodeMesh(s.translucent).vertices
+	}
+	s.dirty = false
+}
+
+// decodeMesh reads a mesher's vertices through its layout's named
+// attributes. Vertices stay in the mesher's local space.
+func decodeMesh(m Mesher) softwareMesh {
+	vertices, indices := m.GetMesh()
+	layout := m.VertexLayout()
+	stride := layout.Floats()
+	if stride == 0 {
+		return softwareMesh{}
+	}
+
+	// Meshes without indices are drawn with DrawArrays.
+	if indices == nil {
+		indices = make([]uint32, len(vertices)/stride)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	if _, ok := layout.Attribute("packed0"); ok {
+		return softwareMesh{vertices: decodePacked(vertices), indices: indices}
+	}
+
+	read := func(name string, v []float32, out []float32) bool {
+		a, ok := layout.Attribute(name)
+		if !ok {
+			return false
+		}
+		copy(out, v[a.Offset/4:a.Offset/4+int(a.Size)])
+		return true
+	}
+
+	decoded := make([]raster.Vertex, len(vertices)/stride)
+	for i := range decoded {
+		v := vertices[i*stride : (i+1)*stride]
+		out := raster.Vertex{Varyings: raster.Varyings{Color: mgl32.Vec4{1, 1, 1, 1}, Layer: -1}}
+
+		var position [3]float32
+		read("position", v, position[:])
+		out.Position = mgl32.Vec4{position[0], position[1], position[2], 1}
+		read("color", v, out.Color[:])
+		read("normal", v, out.Normal[:])
+		read("uv", v, out.UV[:])
+		var layer [1]float32
+		if read("layer", v, layer[:]) {
+			out.Layer = int(layer[0] + 0.5)
+		}
+		decoded[i] = out
+	}
+	return softwareMesh{vertices: decoded, indices: indices}
+}
+
+// decodePacked unpacks PackedLayout vertices the way the packed vertex
+// shader does.
+func decodePacked(vertices []float32) []raster.Vertex {
+	decoded := make([]raster.Vertex, len(vertices)/2)
+	for i := range decoded {
+		p := UnpackVertex([2]uint32{math.Float32bits(vertices[i*2]), math.Float32bits(vertices[i*2+1])})
+		position := p.Position()
+		shade := float32(p.AO) / maxPackedAO * float32(p.Light) / maxPackedLight
+		c := p.ColorValue()
+		decoded[i] = raster.Vertex{
+			Position: mgl32.Vec4{position[0], position[1], position[2], 1},
+			Varyings: raster.Varyings{
+				Color:  mgl32.Vec4{c[0] * shade, c[1] * shade, c[2] * shade, 1},
+				Normal: mgl32.Vec3(PackedNormal(p.Normal)),
+				Layer:  -1,
+			},
+		}
+	}
+	return decoded
+}
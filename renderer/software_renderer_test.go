@@ -0,0 +1,147 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testCamera struct {
+	eye, target mgl32.Vec3
+}
+
+func (c testCamera) GetViewMatrix() mgl32.Mat4 {
+	return mgl32.LookAtV(c.eye, c.target, mgl32.Vec3{0, 1, 0})
+}
+
+func (c testCamera) GetProjectionMatrix() mgl32.Mat4 {
+	return mgl32.Perspective(mgl32.DegToRad(45), 1, 0.1, 100)
+}
+
+func (c testCamera) GetPosition() mgl32.Vec3 {
+	return c.eye
+}
+
+func (c testCamera) GetDirection() mgl32.Vec3 {
+	return c.target.Sub(c.eye).Normalize()
+}
+
+func TestSoftwareRendererDrawsEveryMesher(t *testing.T) {
+	clear := color.RGBA{0, 0, 0, 255}
+	for _, name := range MesherNames() {
+		mesher, err := NewMesher(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewSoftwareRenderer(mesher, 64, 64)
+		r.SetClearColor(clear)
+		r.SetTextures([]image.Image{image.NewUniform(color.RGBA{255, 255, 255, 255})})
+		r.SetCamera(testCamera{eye: mgl32.Vec3{4, 12, 24}, target: mgl32.Vec3{4, 4, 4}})
+		for _, cube := range scene.Solid(8) {
+			r.AddCube(cube)
+		}
+		r.Render()
+
+		if got := r.Image().RGBAAt(32, 32); got == clear {
+			t.Errorf("%s: centre pixel is the clear colour", name)
+		}
+		if got := r.Image().RGBAAt(0, 0); got != clear {
+			t.Errorf("%s: corner pixel = %v, want the clear colour", name, got)
+		}
+	}
+}
+
+func TestSoftwareRendererCullsSectionsBehindCamera(t *testing.T) {
+	r := NewSoftwareRenderer(NewGreedyMesher(), 32, 32)
+	r.SetCamera(testCamera{eye: mgl32.Vec3{8, 8, 40}, target: mgl32.Vec3{8, 8, 80}})
+	for _, cube := range scene.Solid(8) {
+		r.AddCube(cube)
+	}
+	r.Render()
+	if stats := r.CullStats(); stats.Visible != 0 || stats.Culled != 1 {
+		t.Errorf("CullStats = %+v, want the only section culled", stats)
+	}
+}
+
+// Like MeshRenderer, sections see the cubes across their borders, so faces
+// where sections meet are meshed as if the world were one section.
+func TestSoftwareRendererMeshesAcrossSections(t *testing.T) {
+	blue := component.Color{0.2, 0.4, 0.9}
+	r := NewSoftwareRenderer(NewSurfaceNetsMesher(), 32, 32)
+	r.SetCamera(testCamera{eye: mgl32.Vec3{16, 8, 60}, target: mgl32.Vec3{16, 8, 8}})
+	solid := scene.Solid(2 * sectionSize)
+	for _, cube := range solid {
+		r.AddCube(cube)
+	}
+	for _, x := range []float32{sectionSize - 1, sectionSize} {
+		water := primitive.Cube{Size: 1, Color: blue, Translucent: true}
+		water.X, water.Y, water.Z = x, 2*sectionSize+1, 0
+		r.AddCube(water)
+	}
+	r.Render()
+
+	triangles, quads := 0, 0
+	for _, section := range r.sections {
+		triangles += len(section.opaque.indices) / 3
+		quads += len(section.translucentVerts) / 4
+	}
+	whole := NewSurfaceNetsMesher()
+	whole.GenerateMesh(solid)
+	if _, indices := whole.GetMesh(); triangles != len(indices)/3 {
+		t.Errorf("sections drew %d surface nets triangles, the whole scene %d", triangles, len(indices)/3)
+	}
+	if quads != 10 {
+		t.Errorf("two water cubes either side of a section border drew %d quads, want 10", quads)
+	}
+}
+
+// Textured faces are lit like coloured ones, so dimming the light darkens a
+// texture-greedy world.
+func TestSoftwareRendererLightsTextures(t *testing.T) {
+	render := func(lighting Lighting) color.RGBA {
+		r := NewSoftwareRenderer(NewTextureGreedyMesher(0), 32, 32)
+		r.SetTextures([]image.Image{image.NewUniform(color.RGBA{255, 255, 255, 255})})
+		r.SetFog(Fog{Mode: FogOff})
+		r.SetLighting(lighting)
+		r.SetCamera(testCamera{eye: mgl32.Vec3{4, 12, 24}, target: mgl32.Vec3{4, 4, 4}})
+		for _, cube := range scene.Solid(8) {
+			r.AddCube(cube)
+		}
+		r.Render()
+		return r.Image().RGBAAt(16, 16)
+	}
+
+	lit := render(DefaultLighting())
+	dim := render(Lighting{SunDirection: mgl32.Vec3{0, -1, 0}, Ambient: 0.2})
+	if dim.R >= lit.R || dim.R > 60 {
+		t.Errorf("dimly lit texture = %v, lit = %v, want about a fifth as bright", dim, lit)
+	}
+}
+
+func TestTextureGreedyNormals(t *testing.T) {
+	m := NewTextureGreedyMesher(0)
+	m.GenerateMesh(scene.Solid(2))
+	layout := m.VertexLayout()
+	normal, ok := layout.Attribute("normal")
+	if !ok {
+		t.Fatal("no normal attribute")
+	}
+	stride := layout.Floats()
+	vertices, indices := m.GetMesh()
+	for i := 0; i < len(indices); i += 3 {
+		n := mgl32.Vec3(vertices[int(indices[i])*stride+normal.Offset/4:][:3])
+		var p [3]mgl32.Vec3
+		for k := range p {
+			p[k] = mgl32.Vec3(vertices[int(indices[i+k])*stride:][:3])
+		}
+		// The winding agrees with the normal.
+		if face := p[1].Sub(p[0]).Cross(p[2].Sub(p[0])); face.Dot(n) <= 0 {
+			t.Fatalf("triangle %v has normal %v against its winding", p, n)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package renderer
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SurfaceNetsMesher builds a smooth isosurface using naive surface nets.
+// Each vertex is position, colour and normal (9 floats).
+type SurfaceNetsMesher struct {
+	meshBuffers
+	vertices []float32
+	indices  []uint32
+}
+
+// cubeEdges lists the 12 edges of a cell as pairs of corner indices. Corner
+// bit 0 is x, bit 1 is y and bit 2 is z.
+var cubeEdges = [12][2]int{
+	{0, 1}, {2, 3}, {4, 5}, {6, 7},
+	{0, 2}, {1, 3}, {4, 6}, {5, 7},
+	{0, 4}, {1, 5}, {2, 6}, {3, 7},
+}
+
+func NewSurfaceNetsMesher() *SurfaceNetsMesher {
+	return &SurfaceNetsMesher{}
+}
+
+func (m *SurfaceNetsMesher) CreateMesh(cubes []primitive.Cube) {
+	m.CreateMeshWithBorder(cubes, nil)
+}
+
+// CreateMeshWithBorder is CreateMesh for one section of a larger world.
+// border holds solid cubes of the neighbouring sections, in the same space.
+// The surface runs on through them instead of closing at the section's
+// edge, and faces whose solid side is a border cube are left to the section
+// that owns it.
+func (m *SurfaceNetsMesher) CreateMeshWithBorder(cubes, border []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, border)
+	m.setupBuffers()
+}
+
+func (m *SurfaceNetsMesher) CreateMeshFromVolume(volume *primitive.Volume) {
+	m.GenerateMeshFromVolume(volume)
+	m.setupBuffers()
+}
+
+func (m *SurfaceNetsMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, nil)
+}
+
+func (m *SurfaceNetsMesher) GenerateMeshWithBorder(cubes, border []primitive.Cube) {
+	owned := make(map[[3]int]bool, len(cubes))
+	for _, cube := range cubes {
+		owned[[3]int{int(cube.X), int(cube.Y), int(cube.Z)}] = true
+	}
+	all := append(append(make([]primitive.Cube, 0, len(cubes)+len(border)), cubes...), border...)
+
+	m.vertices = nil
+	m.indices = nil
+	m.generateMesh(primitive.NewVolumeFromCubes(all), func(x, y, z int) bool {
+		return owned[[3]int{x, y, z}]
+	})
+}
+
+func (m *SurfaceNetsMesher) GenerateMeshFromVolume(volume *primitive.Volume) {
+	m.vertices = nil
+	m.indices = nil
+	m.generateMesh(volume, nil)
+}
+
+func (m *SurfaceNetsMesher) VertexLayout() VertexLayout {
+	return PositionColorNormalLayout
+}
+
+func (m *SurfaceNetsMesher) Bind() {
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *SurfaceNetsMesher) Unbind() {
+	gl.BindVertexArray(0)
+}
+
+func (m *SurfaceNetsMesher) Draw() {
+	m.EnableBackfaceCulling()
+	gl.BindVertexArray(m.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(0)
+}
+
+func (m *SurfaceNetsMesher) GetMesh() ([]float32, []uint32) {
+	return m.vertices, m.indices
+}
+
+func (m *SurfaceNetsMesher) String() string {
+	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
+}
+
+func (m *SurfaceNetsMesher) Clone() Mesher {
+	return NewSurfaceNetsMesher()
+}
+
+func (m *SurfaceNetsMesher) CacheKey() string {
+	return "surface-nets/2"
+}
+
+func (m *SurfaceNetsMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.vertices = append(m.vertices[:0], vertices...)
+	m.indices = append(m.indices[:0], indices...)
+	m.setupBuffers()
+}
+
+// generateMesh meshes volume. When owns is set, only faces whose solid
+// sample it reports, in world cells, are kept.
+func (m *SurfaceNetsMesher) generateMesh(volume *primitive.Volume, owns func(x, y, z int) bool) {
+	w, h, d := volume.Width, volume.Height, volume.Depth
+	if w < 2 || h < 2 || d < 2 {
+		return
+	}
+
+	// Cell vertices are added the first time a face uses them, so cells
+	// that only border faces of other sections add none.
+	cellVertex := make([]int32, w*h*d)
+	for i := range cellVertex {
+		cellVertex[i] = -1
+	}
+	vertex := func(cell [3]int) int32 {
+		i := cell[0] + cell[1]*w + cell[2]*w*h
+		if cellVertex[i] >= 0 {
+			return cellVertex[i]
+		}
+		var corners [8]float32
+		var mask uint8
+		for c := 0; c < 8; c++ {
+			corners[c] = volume.Density(cell[0]+c&1, cell[1]+(c>>1)&1, cell[2]+(c>>2)&1)
+			if corners[c] < 0 {
+				mask |= 1 << c
+			}
+		}
+		if mask == 0 || mask == 0xff {
+			return -1
+		}
+		cellVertex[i] = int32(len(m.vertices) / 9)
+		m.addCellVertex(volume, cell, corners, mask)
+		return cellVertex[i]
+	}
+
+	for z := 0; z < d-1; z++ {
+		for y := 0; y < h-1; y++ {
+			for x := 0; x < w-1; x++ {
+				m.addCellFaces(volume, [3]int{x, y, z}, vertex, owns)
+			}
+		}
+	}
+}
+
+func (m *SurfaceNetsMesher) addCellVertex(volume *primitive.Volume, cell [3]int, corners [8]float32, mask uint8) {
+	var sum mgl32.Vec3
+	crossings := 0
+	for _, edge := range cubeEdges {
+		a, b := edge[0], edge[1]
+		if (mask>>a)&1 == (mask>>b)&1 {
+			continue
+		}
+		t := corners[a] / (corners[a] - corners[b])
+		pa := cornerOffset(a)
+		pb := cornerOffset(b)
+		sum = sum.Add(pa.Add(pb.Sub(pa).Mul(t)))
+		crossings++
+	}
+	p := sum.Mul(1 / float32(crossings))
+
+	// Distance grows outward, so the gradient is the outward normal.
+	normal := mgl32.Vec3{
+		(corners[1] - corners[0]) + (corners[3] - corners[2]) + (corners[5] - corners[4]) + (corners[7] - corners[6]),
+		(corners[2] - corners[0]) + (corners[3] - corners[1]) + (corners[6] - corners[4]) + (corners[7] - corners[5]),
+		(corners[4] - corners[0]) + (corners[5] - corners[1]) + (corners[6] - corners[2]) + (corners[7] - corners[3]),
+	}
+	if normal.Len() > 0 {
+		normal = normal.Normalize()
+	}
+
+	var color component.Color
+	inside := 0
+	for i := 0; i < 8; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		c := volume.Color(cell[0]+i&1, cell[1]+(i>>1)&1, cell[2]+(i>>2)&1)
+		color[0] += c[0]
+		color[1] += c[1]
+		color[2] += c[2]
+		inside++
+	}
+	for i := range color {
+		color[i] /= float32(inside)
+	}
+
+	// Samples sit at voxel centres so the surface lines up with the blocky meshers.
+	x := float32(volume.Min[0]+cell[0]) + 0.5 + p[0]
+	y := float32(volume.Min[1]+cell[1]) + 0.5 + p[1]
+	z := float32(volume.Min[2]+cell[2]) + 0.5 + p[2]
+	m.vertices = append(m.vertices,
+		x, y, z, color[0], color[1], color[2], normal[0], normal[1], normal[2],
+	)
+}
+
+func (m *SurfaceNetsMesher) addCellFaces(volume *primitive.Volume, cell [3]int, vertex func([3]int) int32, owns func(x, y, z int) bool) {
+	inside := volume.Density(cell[0], cell[1], cell[2]) < 0
+	for axis := 0; axis < 3; axis++ {
+		next := cell
+		next[axis]++
+		if inside == (volume.Density(next[0], next[1], next[2]) < 0) {
+			continue
+		}
+
+		u := (axis + 1) % 3
+		v := (axis + 2) % 3
+		if cell[u] == 0 || cell[v] == 0 {
+			continue
+		}
+
+		solid := cell
+		if !inside {
+			solid = next
+		}
+		if owns != nil && !owns(solid[0]+volume.Min[0], solid[1]+volume.Min[1], solid[2]+volume.Min[2]) {
+			continue
+		}
+
+		du := [3]int{}
+		dv := [3]int{}
+		du[u] = 1
+		dv[v] = 1
+
+		a := vertex(cell)
+		b := vertex([3]int{cell[0] - du[0], cell[1] - du[1], cell[2] - du[2]})
+		c := vertex([3]int{cell[0] - du[0] - dv[0], cell[1] - du[1] - dv[1], cell[2] - du[2] - dv[2]})
+		e := vertex([3]int{cell[0] - dv[0], cell[1] - dv[1], cell[2] - dv[2]})
+		if a < 0 || b < 0 || c < 0 || e < 0 {
+			continue
+		}
+
+		if inside {
+			m.indices = append(m.indices,
+				uint32(a), uint32(b), uint32(c),
+				uint32(a), uint32(c), uint32(e),
+			)
+		} else {
+			m.indices = append(m.indices,
+				uint32(a), uint32(c), uint32(b),
+				uint32(a), uint32(e), uint32(c),
+			)
+		}
+	}
+}
+
+func cornerOffset(corner int) mgl32.Vec3 {
+	return mgl32.Vec3{float32(corner & 1), float32((corner >> 1) & 1), float32((corner >> 2) & 1)}
+}
+
+func (m *SurfaceNetsMesher) setupBuffers() {
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.STATIC_DRAW)
+}
+
+func (m *SurfaceNetsMesher) EnableBackfaceCulling() {
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.FrontFace(gl.CCW)
+}
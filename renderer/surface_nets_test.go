@@ -0,0 +1,119 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// solidBox fills an n³ box of cells starting at the origin.
+func solidBox(n int) []primitive.Cube {
+	var cubes []primitive.Cube
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			for z := 0; z < n; z++ {
+				cube := primitive.Cube{Size: 1}
+				cube.X, cube.Y, cube.Z = float32(x), float32(y), float32(z)
+				cubes = append(cubes, cube)
+			}
+		}
+	}
+	return cubes
+}
+
+func TestSurfaceNetsSolidBoxIsClosed(t *testing.T) {
+	// Position, colour and normal.
+	const stride = 9
+	for _, n := range []int{1, 2, 5} {
+		m := NewSurfaceNetsMesher()
+		m.generateMesh(primitive.NewVolumeFromCubes(solidBox(n)), nil)
+		vertices, indices := m.GetMesh()
+
+		// One vertex per cell between samples that straddles the surface.
+		if got, want := len(vertices)/stride, (n+1)*(n+1)*(n+1)-(n-1)*(n-1)*(n-1); got != want {
+			t.Errorf("%d³ box: %d vertices, want %d", n, got, want)
+		}
+
+		// Closed and consistently wound: every edge is used once in each
+		// direction.
+		edges := map[[2]uint32]int{}
+		for i := 0; i < len(indices); i += 3 {
+			for k := 0; k < 3; k++ {
+				edges[[2]uint32{indices[i+k], indices[i+(k+1)%3]}]++
+			}
+		}
+		for edge, count := range edges {
+			if count != 1 || edges[[2]uint32{edge[1], edge[0]}] != 1 {
+				t.Fatalf("%d³ box: edge %v used %d times, reverse %d times", n, edge, count, edges[[2]uint32{edge[1], edge[0]}])
+			}
+		}
+
+		// A closed surface with the topology of a sphere has V - E + F = 2.
+		v, e, f := len(vertices)/stride, len(edges)/2, len(indices)/3
+		if v-e+f != 2 {
+			t.Errorf("%d³ box: V - E + F = %d, want 2", n, v-e+f)
+		}
+
+		// Triangles face outwards, away from the box's centre.
+		centre := mgl32.Vec3{float32(n) / 2, float32(n) / 2, float32(n) / 2}
+		for i := 0; i < len(indices); i += 3 {
+			var p [3]mgl32.Vec3
+			for k := range p {
+				p[k] = mgl32.Vec3(vertices[int(indices[i+k])*stride:][:3])
+			}
+			face := p[1].Sub(p[0]).Cross(p[2].Sub(p[0]))
+			mid := p[0].Add(p[1]).Add(p[2]).Mul(1.0 / 3)
+			if face.Dot(mid.Sub(centre)) <= 0 {
+				t.Fatalf("%d³ box: triangle %v faces inwards", n, p)
+			}
+		}
+	}
+}
+
+// Meshed a section at a time with each section's border, a box spanning
+// eight sections draws the same surface as one mesh of the whole box, with
+// no walls where the sections meet.
+func TestSurfaceNetsJoinsSections(t *testing.T) {
+	const stride, n = 9, 2 * sectionSize
+	cubes := solidBox(n)
+	sections := make(map[sectionKey]*meshSection)
+	for _, cube := range cubes {
+		key := sectionOf(cube.X, cube.Y, cube.Z)
+		if sections[key] == nil {
+			sections[key] = &meshSection{key: key}
+		}
+		sections[key].cubes = append(sections[key].cubes, cube)
+	}
+
+	triangles := 0
+	for key, section := range sections {
+		m := NewSurfaceNetsMesher()
+		m.GenerateMeshWithBorder(section.toLocal(section.cubes), section.toLocal(section.border(sections)))
+		vertices, indices := m.GetMesh()
+		triangles += len(indices) / 3
+
+		origin := key.origin()
+		for i := 0; i < len(indices); i += 3 {
+			var mid mgl32.Vec3
+			for k := 0; k < 3; k++ {
+				mid = mid.Add(mgl32.Vec3(vertices[int(indices[i+k])*stride:][:3]).Add(origin).Mul(1.0 / 3))
+			}
+			inside := true
+			for a := 0; a < 3; a++ {
+				if mid[a] < 2 || mid[a] > n-2 {
+					inside = false
+				}
+			}
+			if inside {
+				t.Fatalf("section %v has a face at %v inside the box", key, mid)
+			}
+		}
+	}
+
+	whole := NewSurfaceNetsMesher()
+	whole.GenerateMesh(cubes)
+	if _, indices := whole.GetMesh(); triangles != len(indices)/3 {
+		t.Errorf("sections drew %d triangles, the whole box %d", triangles, len(indices)/3)
+	}
+}
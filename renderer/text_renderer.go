@@ -0,0 +1,144 @@
+package renderer
+
+import (
+	"github.com/dfirebaugh/cube/pkg/text"
+	"github.com/dfirebaugh/cube/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+var textLayout = VertexLayout{
+	Stride: 8 * 4,
+	Attributes: []VertexAttribute{
+		{Name: "position", Location: 0, Size: 2, Type: gl.FLOAT, Offset: 0},
+		{Name: "uv", Location: 1, Size: 2, Type: gl.FLOAT, Offset: 2 * 4},
+		{Name: "color", Location: 2, Size: 4, Type: gl.FLOAT, Offset: 4 * 4},
+	},
+}
+
+// TextRenderer draws text and rectangles over the screen in pixels from
+// the top left corner. Calls to DrawText and DrawRect are queued and drawn
+// by the next Render, which then clears the queue.
+type TextRenderer struct {
+	window  Window
+	font    *text.Font
+	scale   float32
+	program uint32
+	atlas   uint32
+	buffers meshBuffers
+
+	vertices []float32
+	indices  []uint32
+}
+
+// NewTextRenderer uses text.Default at twice its size.
+func NewTextRenderer() *TextRenderer {
+	program, err := shader.NewProgramFromFiles("text_vertex_shader.glsl", "text_fragment_shader.glsl")
+	if err != nil {
+		logrus.Fatalln("failed to create text program:", err)
+	}
+	t := &TextRenderer{font: text.Default(), scale: 2, program: program}
+	t.uploadAtlas()
+	return t
+}
+
+func (t *TextRenderer) uploadAtlas() {
+	atlas := t.font.Atlas
+	gl.GenTextures(1, &t.atlas)
+	gl.BindTexture(gl.TEXTURE_2D, t.atlas)
+	// Glyph rows aren't a multiple of four bytes wide.
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, int32(atlas.Rect.Dx()), int32(atlas.Rect.Dy()), 0, gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(atlas.Pix))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (t *TextRenderer) SetWindow(window Window) {
+	t.window = window
+}
+
+func (t *TextRenderer) Font() *text.Font {
+	return t.font
+}
+
+// SetScale sets how many screen pixels each font pixel covers.
+func (t *TextRenderer) SetScale(scale float32) {
+	t.scale = scale
+}
+
+// Measure returns the size s takes up on screen.
+func (t *TextRenderer) Measure(s string) (float32, float32) {
+	return t.font.Measure(s, t.scale)
+}
+
+// DrawText queues s with its top left corner at x, y.
+func (t *TextRenderer) DrawText(x, y float32, s string, color mgl32.Vec4) {
+	for _, q := range t.font.Layout(s, x, y, t.scale) {
+		t.quad(q, color)
+	}
+}
+
+// DrawRect queues a solid rectangle, such as a panel behind text.
+func (t *TextRenderer) DrawRect(x0, y0, x1, y1 float32, color mgl32.Vec4) {
+	t.quad(text.Quad{X0: x0, Y0: y0, X1: x1, Y1: y1, U0: -1, V0: -1, U1: -1, V1: -1}, color)
+}
+
+func (t *TextRenderer) quad(q text.Quad, c mgl32.Vec4) {
+	base := uint32(len(t.vertices) / textLayout.Floats())
+	t.vertices = append(t.vertices,
+		q.X0, q.Y0, q.U0, q.V0, c[0], c[1], c[2], c[3],
+		q.X1, q.Y0, q.U1, q.V0, c[0], c[1], c[2], c[3],
+		q.X1, q.Y1, q.U1, q.V1, c[0], c[1], c[2], c[3],
+		q.X0, q.Y1, q.U0, q.V1, c[0], c[1], c[2], c[3],
+	)
+	t.indices = append(t.indices, base, base+1, base+2, base, base+2, base+3)
+}
+
+// Render draws everything queued since the last Render over the frame.
+func (t *TextRenderer) Render() {
+	if len(t.indices) == 0 || t.window == nil {
+		return
+	}
+	width, height := t.window.GetSize()
+	projection := mgl32.Ortho(0, float32(width), float32(height), 0, -1, 1)
+
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	blend := gl.IsEnabled(gl.BLEND)
+	cull := gl.IsEnabled(gl.CULL_FACE)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	gl.UseProgram(t.program)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(t.program, gl.Str("projection\x00")), 1, false, &projection[0])
+	gl.Uniform1i(gl.GetUniformLocation(t.program, gl.Str("fontAtlas\x00")), 0)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, t.atlas)
+
+	t.buffers.uploadIndexed(t.vertices, t.indices, textLayout, gl.STREAM_DRAW)
+	gl.BindVertexArray(t.buffers.vao)
+	gl.DrawElementsWithOffset(gl.TRIANGLES, int32(len(t.indices)), gl.UNSIGNED_INT, 0)
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.Enable(gl.DEPTH_TEST)
+	if cull {
+		gl.Enable(gl.CULL_FACE)
+	}
+	if !blend {
+		gl.Disable(gl.BLEND)
+	}
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	checkGLError("TextRenderer")
+
+	t.vertices = t.vertices[:0]
+	t.indices = t.indices[:0]
+}
@@ -12,26 +12,10 @@ import (
 )
 
 func LoadTexture(file string) (uint32, error) {
-	imgFile, err := os.Open(file)
+	rgba, err := loadRGBA(file)
 	if err != nil {
-		log.Printf("Failed to open texture file: %s\n", file)
 		return 0, err
 	}
-	defer imgFile.Close()
-
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		log.Printf("Failed to decode texture file: %s\n", file)
-		return 0, err
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
-	if rgba.Stride != rgba.Rect.Size().X*4 {
-		log.Printf("Unsupported stride in texture file: %s\n", file)
-		return 0, fmt.Errorf("unsupported stride")
-	}
-
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 
 	var texture uint32
 	gl.GenTextures(1, &texture)
@@ -47,3 +31,70 @@ func LoadTexture(file string) (uint32, error) {
 
 	return texture, nil
 }
+
+// LoadTextureArray uploads each file as one layer of a GL_TEXTURE_2D_ARRAY.
+// All files must have the same dimensions. Textures repeat so merged quads
+// can tile them.
+func LoadTextureArray(files ...string) (uint32, error) {
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no texture files")
+	}
+
+	layers := make([]*image.RGBA, len(files))
+	for i, file := range files {
+		rgba, err := loadRGBA(file)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 && rgba.Rect.Size() != layers[0].Rect.Size() {
+			log.Printf("Texture size mismatch in texture array: %s\n", file)
+			return 0, fmt.Errorf("texture %s is %v, want %v", file, rgba.Rect.Size(), layers[0].Rect.Size())
+		}
+		layers[i] = rgba
+	}
+
+	width := int32(layers[0].Rect.Size().X)
+	height := int32(layers[0].Rect.Size().Y)
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, texture)
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA8, width, height, int32(len(layers)), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	for i, rgba := range layers {
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(i), width, height, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	}
+	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.NEAREST_MIPMAP_LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.REPEAT)
+
+	log.Printf("Texture array loaded with ID: %d (%d layers)\n", texture, len(layers))
+
+	return texture, nil
+}
+
+func loadRGBA(file string) (*image.RGBA, error) {
+	imgFile, err := os.Open(file)
+	if err != nil {
+		log.Printf("Failed to open texture file: %s\n", file)
+		return nil, err
+	}
+	defer imgFile.Close()
+
+	img, _, err := image.Decode(imgFile)
+	if err != nil {
+		log.Printf("Failed to decode texture file: %s\n", file)
+		return nil, err
+	}
+
+	rgba := image.NewRGBA(img.Bounds())
+	if rgba.Stride != rgba.Rect.Size().X*4 {
+		log.Printf("Unsupported stride in texture file: %s\n", file)
+		return nil, fmt.Errorf("unsupported stride")
+	}
+
+	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
+	return rgba, nil
+}
@@ -0,0 +1,139 @@
+package renderer
+
+import (
+	"sort"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// blockLayout matches primitive.Cube.Vertices: position, uv and normal.
+var blockLayout = VertexLayout{
+	Stride: 8 * 4,
+	Attributes: []VertexAttribute{
+		{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+		{Name: "uv", Location: 1, Size: 2, Type: gl.FLOAT, Offset: 3 * 4},
+		{Name: "normal", Location: 2, Size: 3, Type: gl.FLOAT, Offset: 5 * 4},
+	},
+}
+
+// textureBatch holds the visible faces of many textured cubes in one buffer,
+// grouped by texture so each texture is a single draw call.
+type textureBatch struct {
+	meshBuffers
+	vertices []float32
+	indices  []uint32
+	ranges   []textureRange
+}
+
+// textureRange is a run of indices drawn with one texture.
+type textureRange struct {
+	texture uint32
+	offset  int
+	count   int
+}
+
+type textureFaces struct {
+	vertices []float32
+	indices  []uint32
+}
+
+// build gathers the visible faces of cubes, offset by their positions, and
+// uploads them.
+func (b *textureBatch) build(cubes []primitive.Cube) {
+	faces := make(map[uint32]*textureFaces)
+	for _, cube := range cubes {
+		if cube.Size == 0 {
+			continue
+		}
+		vertices := cube.Vertices()
+		indices := cube.Indices(0)
+		for i, texture := range visibleFaceTextures(cube) {
+			group, ok := faces[texture]
+			if !ok {
+				group = &textureFaces{}
+				faces[texture] = group
+			}
+
+			base := uint32(len(group.vertices) / 8)
+			face := vertices[i*32 : (i+1)*32]
+			for v := 0; v < 4; v++ {
+				vertex := face[v*8 : (v+1)*8]
+				group.vertices = append(group.vertices,
+					vertex[0]+cube.X, vertex[1]+cube.Y, vertex[2]+cube.Z,
+					vertex[3], vertex[4], vertex[5], vertex[6], vertex[7],
+				)
+			}
+			for _, idx := range indices[i*6 : (i+1)*6] {
+				group.indices = append(group.indices, base+idx-uint32(i*4))
+			}
+		}
+	}
+
+	textures := make([]uint32, 0, len(faces))
+	for texture := range faces {
+		textures = append(textures, texture)
+	}
+	sort.Slice(textures, func(i, j int) bool { return textures[i] < textures[j] })
+
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+	b.ranges = b.ranges[:0]
+	for _, texture := range textures {
+		group := faces[texture]
+		base := uint32(len(b.vertices) / 8)
+		b.ranges = append(b.ranges, textureRange{texture: texture, offset: len(b.indices), count: len(group.indices)})
+		b.vertices = append(b.vertices, group.vertices...)
+		for _, idx := range group.indices {
+			b.indices = append(b.indices, base+idx)
+		}
+	}
+
+	b.uploadIndexed(b.vertices, b.indices, blockLayout, gl.STATIC_DRAW)
+}
+
+// visibleFaceTextures lists the texture of each face cube.Vertices emits, in
+// the same order.
+func visibleFaceTextures(cube primitive.Cube) []uint32 {
+	if cube.ShouldHide {
+		return nil
+	}
+	faces := []struct {
+		hidden  bool
+		texture uint32
+	}{
+		{cube.HideFront, cube.Front},
+		{cube.HideBack, cube.Back},
+		{cube.HideLeft, cube.Left},
+		{cube.HideRight, cube.Right},
+		{cube.HideTop, cube.Top},
+		{cube.HideBottom, cube.Bottom},
+	}
+	var textures []uint32
+	for _, face := range faces {
+		if !face.hidden {
+			textures = append(textures, face.texture)
+		}
+	}
+	return textures
+}
+
+// draw issues one draw call per texture, sampling from texture unit 0.
+func (b *textureBatch) draw() {
+	if len(b.ranges) == 0 {
+		return
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindVertexArray(b.vao)
+	for _, r := range b.ranges {
+		gl.BindTexture(gl.TEXTURE_2D, r.texture)
+		gl.DrawElementsWithOffset(gl.TRIANGLES, int32(r.count), gl.UNSIGNED_INT, uintptr(r.offset*4))
+	}
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// size counts the batch's vertices and triangles.
+func (b *textureBatch) size() (vertices, triangles int) {
+	return len(b.vertices) / 8, len(b.indices) / 3
+}
@@ -0,0 +1,263 @@
+package renderer
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// TextureGreedyMesher merges faces that share a direction and texture layer.
+// Each vertex is position, uv, layer and normal (9 floats). UVs run from 0 to the
+// quad's width and height so a repeating texture array tiles across it.
+type TextureGreedyMesher struct {
+	meshBuffers
+	vertices     []float32
+	indices      []uint32
+	size         int
+	textureArray uint32
+
+	// cells holds the index+1 of the cube occupying each cell.
+	cells []int32
+	mask  []int64
+}
+
+func NewTextureGreedyMesher(textureArray uint32) *TextureGreedyMesher {
+	return &TextureGreedyMesher{
+		size:         greedyMesherSize,
+		textureArray: textureArray,
+	}
+}
+
+func (m *TextureGreedyMesher) CreateMesh(cubes []primitive.Cube) {
+	m.GenerateMesh(cubes)
+	m.setupBuffers()
+}
+
+func (m *TextureGreedyMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.vertices = m.vertices[:0]
+	m.indices = m.indices[:0]
+	m.populateCells(cubes)
+	for d := 0; d < 3; d++ {
+		m.generateDirectionMesh(d, cubes)
+	}
+}
+
+func (m *TextureGreedyMesher) VertexLayout() VertexLayout {
+	return PositionUVLayerNormalLayout
+}
+
+func (m *TextureGreedyMesher) Shaders() (string, string) {
+	return "texture_array_vertex_shader.glsl", "texture_array_fragment_shader.glsl"
+}
+
+func (m *TextureGreedyMesher) Bind() {
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, m.textureArray)
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *TextureGreedyMesher) Unbind() {
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+}
+
+func (m *TextureGreedyMesher) Draw() {
+	m.EnableBackfaceCulling()
+	gl.BindVertexArray(m.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(0)
+}
+
+func (m *TextureGreedyMesher) GetMesh() ([]float32, []uint32) {
+	return m.vertices, m.indices
+}
+
+func (m *TextureGreedyMesher) String() string {
+	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
+}
+
+func (m *TextureGreedyMesher) Clone() Mesher {
+	return &TextureGreedyMesher{size: m.size, textureArray: m.textureArray}
+}
+
+func (m *TextureGreedyMesher) CacheKey() string {
+	return fmt.Sprintf("texture-greedy/2/size=%d", m.size)
+}
+
+func (m *TextureGreedyMesher) CreateMeshFromData(vertices []float32, indices []uint32) {
+	m.vertices = append(m.vertices[:0], vertices...)
+	m.indices = append(m.indices[:0], indices...)
+	m.setupBuffers()
+}
+
+func (m *TextureGreedyMesher) populateCells(cubes []primitive.Cube) {
+	n := m.size
+	if len(m.cells) != n*n*n {
+		m.cells = make([]int32, n*n*n)
+		m.mask = make([]int64, n*n)
+	}
+	for i := range m.cells {
+		m.cells[i] = 0
+	}
+
+	for i, cube := range cubes {
+		x, y, z := int(cube.X), int(cube.Y), int(cube.Z)
+		if cube.Size == 0 || x < 0 || x >= n || y < 0 || y >= n || z < 0 || z >= n {
+			continue
+		}
+		m.cells[x+y*n+z*n*n] = int32(i + 1)
+	}
+}
+
+func (m *TextureGreedyMesher) cubeAt(x [3]int) int32 {
+	n := m.size
+	if x[0] < 0 || x[0] >= n || x[1] < 0 || x[1] >= n || x[2] < 0 || x[2] >= n {
+		return 0
+	}
+	return m.cells[x[0]+x[1]*n+x[2]*n*n]
+}
+
+// faceLayer returns the texture layer of the face of cube pointing along
+// axis d, in the positive direction when positive is set.
+func faceLayer(cube primitive.Cube, d int, positive bool) uint32 {
+	switch {
+	case d == 0 && positive:
+		return cube.Layers.Right
+	case d == 0:
+		return cube.Layers.Left
+	case d == 1 && positive:
+		return cube.Layers.Top
+	case d == 1:
+		return cube.Layers.Bottom
+	case d == 2 && positive:
+		return cube.Layers.Front
+	default:
+		return cube.Layers.Back
+	}
+}
+
+func (m *TextureGreedyMesher) generateDirectionMesh(d int, cubes []primitive.Cube) {
+	n := m.size
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	x := [3]int{}
+	q := [3]int{}
+	q[d] = 1
+
+	for p := 0; p <= n; p++ {
+		// Mask values are layer+1, negated for faces pointing along -d.
+		x[d] = p
+		for x[v] = 0; x[v] < n; x[v]++ {
+			for x[u] = 0; x[u] < n; x[u]++ {
+				behind := m.cubeAt([3]int{x[0] - q[0], x[1] - q[1], x[2] - q[2]})
+				front := m.cubeAt(x)
+				var value int64
+				if behind != 0 && front == 0 {
+					value = int64(faceLayer(cubes[behind-1], d, true)) + 1
+				} else if behind == 0 && front != 0 {
+					value = -(int64(faceLayer(cubes[front-1], d, false)) + 1)
+				}
+				m.mask[x[u]+x[v]*n] = value
+			}
+		}
+
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; {
+				value := m.mask[i+j*n]
+				if value == 0 {
+					i++
+					continue
+				}
+
+				w := 1
+				for i+w < n && m.mask[i+w+j*n] == value {
+					w++
+				}
+				h := 1
+			grow:
+				for j+h < n {
+					for k := 0; k < w; k++ {
+						if m.mask[i+k+(j+h)*n] != value {
+							break grow
+						}
+					}
+					h++
+				}
+
+				for l := 0; l < h; l++ {
+					for k := 0; k < w; k++ {
+						m.mask[i+k+(j+l)*n] = 0
+					}
+				}
+
+				x[u], x[v] = i, j
+				layer := value - 1
+				if value < 0 {
+					layer = -value - 1
+				}
+				m.addQuad(d, x, w, h, value > 0, float32(layer))
+				i += w
+			}
+		}
+	}
+}
+
+// textureAxes holds the world axes that s and t run along on faces
+// perpendicular to each axis.
+var textureAxes = [3][2]int{
+	{2, 1}, // ±x faces: width along z
+	{0, 2}, // ±y faces: width along x
+	{0, 1}, // ±z faces: width along x
+}
+
+func (m *TextureGreedyMesher) addQuad(d int, x [3]int, w, h int, positive bool, layer float32) {
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	idx := uint32(len(m.vertices) / PositionUVLayerNormalLayout.Floats())
+	var normal [3]float32
+	if positive {
+		normal[d] = 1
+	} else {
+		normal[d] = -1
+	}
+
+	// s runs along the face's width and t along its height, which is the
+	// world y axis on side faces so textures stay upright.
+	sAxis, tAxis := textureAxes[d][0], textureAxes[d][1]
+	corners := [4][2]int{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	for _, c := range corners {
+		p := x
+		p[u] += c[0]
+		p[v] += c[1]
+
+		s, t := float32(p[sAxis]-x[sAxis]), float32(p[tAxis]-x[tAxis])
+		m.vertices = append(m.vertices,
+			float32(p[0]), float32(p[1]), float32(p[2]), s, t, layer, normal[0], normal[1], normal[2],
+		)
+	}
+
+	// u x v points along +d, so the corners are counter-clockwise seen from +d.
+	if positive {
+		m.indices = append(m.indices,
+			idx, idx+1, idx+2,
+			idx, idx+2, idx+3,
+		)
+	} else {
+		m.indices = append(m.indices,
+			idx, idx+2, idx+1,
+			idx, idx+3, idx+2,
+		)
+	}
+}
+
+func (m *TextureGreedyMesher) setupBuffers() {
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.STATIC_DRAW)
+}
+
+func (m *TextureGreedyMesher) EnableBackfaceCulling() {
+	gl.Enable(gl.CULL_FACE)
+	gl.CullFace(gl.BACK)
+	gl.FrontFace(gl.CCW)
+}
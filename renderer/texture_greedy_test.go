@@ -0,0 +1,170 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// textureQuad is one quad of a TextureGreedyMesher mesh.
+type textureQuad struct {
+	min, max        mgl32.Vec3
+	uvMin, uvMax    mgl32.Vec2
+	points          [4]mgl32.Vec3
+	uvs             [4]mgl32.Vec2
+	layer           float32
+	normal          mgl32.Vec3
+	consistentLayer bool
+}
+
+// textureQuads splits a mesh into its quads. Each quad is four vertices and
+// six indices, and its normal comes from the winding of its first triangle.
+func textureQuads(m *TextureGreedyMesher) []textureQuad {
+	vertices, indices := m.GetMesh()
+	if len(indices) == 0 {
+		return nil
+	}
+	stride := len(vertices) * 6 / (4 * len(indices))
+	var quads []textureQuad
+	for i := 0; i+4*stride <= len(vertices); i += 4 * stride {
+		q := textureQuad{consistentLayer: true}
+		for k := 0; k < 4; k++ {
+			v := vertices[i+k*stride:]
+			p, uv := mgl32.Vec3{v[0], v[1], v[2]}, mgl32.Vec2{v[3], v[4]}
+			q.points[k], q.uvs[k] = p, uv
+			if k == 0 {
+				q.min, q.max, q.uvMin, q.uvMax = p, p, uv, uv
+				q.layer = v[5]
+			}
+			for a := 0; a < 3; a++ {
+				q.min[a], q.max[a] = min(q.min[a], p[a]), max(q.max[a], p[a])
+			}
+			for a := 0; a < 2; a++ {
+				q.uvMin[a], q.uvMax[a] = min(q.uvMin[a], uv[a]), max(q.uvMax[a], uv[a])
+			}
+			if v[5] != q.layer {
+				q.consistentLayer = false
+			}
+		}
+
+		tri := indices[len(quads)*6:]
+		var p [3]mgl32.Vec3
+		for k := range p {
+			p[k] = mgl32.Vec3(vertices[int(tri[k])*stride:][:3])
+		}
+		face := p[1].Sub(p[0]).Cross(p[2].Sub(p[0]))
+		for a := 0; a < 3; a++ {
+			if face[a] > 0 {
+				q.normal[a] = 1
+			} else if face[a] < 0 {
+				q.normal[a] = -1
+			}
+		}
+		quads = append(quads, q)
+	}
+	return quads
+}
+
+// uvAxes returns the world axes s and t should follow on a face: its width
+// and the world y axis on side faces, and x then z on tops and bottoms.
+func uvAxes(normal mgl32.Vec3) (s, t int) {
+	switch {
+	case normal[0] != 0:
+		return 2, 1
+	case normal[2] != 0:
+		return 0, 1
+	default:
+		return 0, 2
+	}
+}
+
+func layeredCube(x, y, z int, layers primitive.CubeLayers) primitive.Cube {
+	cube := primitive.Cube{Size: 1, Layers: layers}
+	cube.X, cube.Y, cube.Z = float32(x), float32(y), float32(z)
+	return cube
+}
+
+func TestTextureGreedyTilesUVs(t *testing.T) {
+	var cubes []primitive.Cube
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 2; y++ {
+			cubes = append(cubes, layeredCube(x, y, 0, primitive.CubeLayers{}))
+		}
+	}
+	m := NewTextureGreedyMesher(0)
+	m.GenerateMesh(cubes)
+
+	quads := textureQuads(m)
+	if len(quads) != 6 {
+		t.Fatalf("a 3x2x1 box with one layer gave %d quads, want 6", len(quads))
+	}
+	for _, q := range quads {
+		size := q.max.Sub(q.min)
+		sAxis, tAxis := uvAxes(q.normal)
+		w, h := size[sAxis], size[tAxis]
+		if q.uvMin != (mgl32.Vec2{}) {
+			t.Errorf("quad with normal %v: UVs start at %v, want 0,0", q.normal, q.uvMin)
+		}
+		if q.uvMax != (mgl32.Vec2{w, h}) {
+			t.Errorf("quad with normal %v covering %v: UVs run to %v, want %v", q.normal, size, q.uvMax, mgl32.Vec2{w, h})
+		}
+	}
+}
+
+func TestTextureGreedySplitsLayers(t *testing.T) {
+	grass := primitive.CubeLayers{Top: 2, Bottom: 1, Front: 1, Back: 1, Left: 1, Right: 1}
+	dirt := primitive.CubeLayers{Top: 1, Bottom: 1, Front: 1, Back: 1, Left: 1, Right: 1}
+	m := NewTextureGreedyMesher(0)
+	m.GenerateMesh([]primitive.Cube{layeredCube(0, 0, 0, grass), layeredCube(1, 0, 0, dirt)})
+
+	perNormal := map[mgl32.Vec3][]float32{}
+	for _, q := range textureQuads(m) {
+		if !q.consistentLayer {
+			t.Errorf("quad with normal %v mixes layers", q.normal)
+		}
+		perNormal[q.normal] = append(perNormal[q.normal], q.layer)
+	}
+	if top := perNormal[mgl32.Vec3{0, 1, 0}]; len(top) != 2 || top[0] == top[1] {
+		t.Errorf("tops with different layers gave layers %v, want two quads", top)
+	}
+	for _, normal := range []mgl32.Vec3{{0, -1, 0}, {0, 0, 1}, {0, 0, -1}} {
+		if got := perNormal[normal]; len(got) != 1 || got[0] != 1 {
+			t.Errorf("faces with normal %v gave layers %v, want one merged quad on layer 1", normal, got)
+		}
+	}
+}
+
+func TestTextureGreedyOrientsSideFaces(t *testing.T) {
+	// Every extent differs so a swapped s and t shows on every face.
+	var cubes []primitive.Cube
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 4; y++ {
+			for z := 0; z < 2; z++ {
+				cubes = append(cubes, layeredCube(x, y, z, primitive.CubeLayers{}))
+			}
+		}
+	}
+	m := NewTextureGreedyMesher(0)
+	m.GenerateMesh(cubes)
+
+	sides := 0
+	for _, q := range textureQuads(m) {
+		if q.normal[1] == 0 {
+			sides++
+			if q.uvMax[1] != 4 {
+				t.Errorf("side with normal %v: t runs to %v, want the box height 4", q.normal, q.uvMax[1])
+			}
+		}
+		sAxis, tAxis := uvAxes(q.normal)
+		for k, p := range q.points {
+			want := mgl32.Vec2{p[sAxis] - q.min[sAxis], p[tAxis] - q.min[tAxis]}
+			if q.uvs[k] != want {
+				t.Errorf("quad with normal %v: vertex %v has UV %v, want %v", q.normal, p, q.uvs[k], want)
+			}
+		}
+	}
+	if sides != 4 {
+		t.Errorf("a 3x4x2 box gave %d side quads, want 4", sides)
+	}
+}
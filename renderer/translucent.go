@@ -0,0 +1,235 @@
+package renderer
+
+import (
+	"fmt"
+	"sort"
+	"unsafe"
+
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const defaultOpacity = 0.5
+
+// TranslucentMesher emits one quad per visible face of each translucent cube.
+// Quads are kept separate so they can be sorted back to front. Cubes span
+// [x, x+1] on each axis, like the greedy meshers.
+type TranslucentMesher struct {
+	meshBuffers
+	vertices []float32
+	indices  []uint32
+	quads    []translucentQuad
+	order    []int
+}
+
+type translucentQuad struct {
+	center   mgl32.Vec3
+	positive bool
+}
+
+func NewTranslucentMesher() *TranslucentMesher {
+	return &TranslucentMesher{}
+}
+
+// CreateMesh takes every cube in the scene. Only translucent cubes produce
+// faces; opaque cubes hide the faces that touch them.
+func (m *TranslucentMesher) CreateMesh(cubes []primitive.Cube) {
+	m.CreateMeshWithBorder(cubes, nil)
+}
+
+// CreateMeshWithBorder is CreateMesh for one section of a larger world.
+// border holds cubes of the neighbouring sections, in the same space, that
+// can hide faces on the section's edge; they produce no geometry.
+func (m *TranslucentMesher) CreateMeshWithBorder(cubes, border []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, border)
+	m.setupBuffers()
+}
+
+func (m *TranslucentMesher) GenerateMesh(cubes []primitive.Cube) {
+	m.GenerateMeshWithBorder(cubes, nil)
+}
+
+func (m *TranslucentMesher) GenerateMeshWithBorder(cubes, border []primitive.Cube) {
+	m.vertices = m.vertices[:0]
+	m.indices = m.indices[:0]
+	m.quads = m.quads[:0]
+
+	cells := make(map[[3]int]primitive.Cube, len(cubes)+len(border))
+	for _, cubes := range [][]primitive.Cube{border, cubes} {
+		for _, cube := range cubes {
+			if cube.ShouldHide {
+				continue
+			}
+			cells[[3]int{int(cube.X), int(cube.Y), int(cube.Z)}] = cube
+		}
+	}
+
+	for _, cube := range cubes {
+		if !cube.Translucent || cube.ShouldHide {
+			continue
+		}
+		pos := [3]int{int(cube.X), int(cube.Y), int(cube.Z)}
+		for d := 0; d < 3; d++ {
+			for _, positive := range []bool{false, true} {
+				neighbor := pos
+				if positive {
+					neighbor[d]++
+				} else {
+					neighbor[d]--
+				}
+				if other, ok := cells[neighbor]; ok && hidesTranslucentFace(cube, other) {
+					continue
+				}
+				m.addQuad(pos, d, positive, cube)
+			}
+		}
+	}
+
+	m.order = m.order[:0]
+	for i := range m.quads {
+		m.order = append(m.order, i)
+	}
+	m.writeIndices()
+}
+
+// hidesTranslucentFace reports whether other covers the face of cube that
+// touches it. Full opaque neighbours always do; translucent ones only when
+// they are the same kind of block.
+func hidesTranslucentFace(cube, other primitive.Cube) bool {
+	if !other.Translucent {
+		return other.IsFull()
+	}
+	return other.Color == cube.Color && opacity(other) == opacity(cube)
+}
+
+func opacity(cube primitive.Cube) float32 {
+	if cube.Opacity == 0 {
+		return defaultOpacity
+	}
+	return cube.Opacity
+}
+
+func (m *TranslucentMesher) addQuad(pos [3]int, d int, positive bool, cube primitive.Cube) {
+	u := (d + 1) % 3
+	v := (d + 2) % 3
+	normal := [3]float32{}
+	p := [3]float32{float32(pos[0]), float32(pos[1]), float32(pos[2])}
+	if positive {
+		p[d]++
+		normal[d] = 1
+	} else {
+		normal[d] = -1
+	}
+
+	alpha := opacity(cube)
+	corners := [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for _, c := range corners {
+		q := p
+		q[u] += c[0]
+		q[v] += c[1]
+		m.vertices = append(m.vertices,
+			q[0], q[1], q[2], cube.Color[0], cube.Color[1], cube.Color[2], alpha, normal[0], normal[1], normal[2],
+		)
+	}
+
+	center := mgl32.Vec3{p[0], p[1], p[2]}
+	center[u] += 0.5
+	center[v] += 0.5
+	m.quads = append(m.quads, translucentQuad{center: center, positive: positive})
+}
+
+// writeIndices emits two triangles per quad in the current order. The
+// corners run counter-clockwise seen from +d, so negative faces are flipped.
+func (m *TranslucentMesher) writeIndices() {
+	m.indices = m.indices[:0]
+	for _, quad := range m.order {
+		idx := uint32(quad * 4)
+		if m.quads[quad].positive {
+			m.indices = append(m.indices,
+				idx, idx+1, idx+2,
+				idx, idx+2, idx+3,
+			)
+		} else {
+			m.indices = append(m.indices,
+				idx, idx+2, idx+1,
+				idx, idx+3, idx+2,
+			)
+		}
+	}
+}
+
+// Sort orders quads from farthest to nearest to eye and uploads the new
+// index buffer if one exists.
+func (m *TranslucentMesher) Sort(eye mgl32.Vec3) {
+	distances := make([]float32, len(m.quads))
+	for i, q := range m.quads {
+		distances[i] = q.center.Sub(eye).LenSqr()
+	}
+	sort.Slice(m.order, func(i, j int) bool {
+		return distances[m.order[i]] > distances[m.order[j]]
+	})
+	m.writeIndices()
+
+	if m.ebo == 0 || len(m.indices) == 0 {
+		return
+	}
+	// The element buffer binding belongs to the VAO.
+	gl.BindVertexArray(m.vao)
+	gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, 0, len(m.indices)*4, gl.Ptr(m.indices))
+	gl.BindVertexArray(0)
+}
+
+func (m *TranslucentMesher) VertexLayout() VertexLayout {
+	return PositionColorAlphaNormalLayout
+}
+
+func (m *TranslucentMesher) Bind() {
+	gl.BindVertexArray(m.vao)
+}
+
+func (m *TranslucentMesher) Unbind() {
+	gl.BindVertexArray(0)
+}
+
+// Draw renders both sides of each quad so the surface stays visible from
+// inside water.
+func (m *TranslucentMesher) Draw() {
+	gl.Disable(gl.CULL_FACE)
+	gl.BindVertexArray(m.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(m.indices)), gl.UNSIGNED_INT, unsafe.Pointer(nil))
+	gl.BindVertexArray(0)
+	gl.Enable(gl.CULL_FACE)
+}
+
+func (m *TranslucentMesher) GetMesh() ([]float32, []uint32) {
+	return m.vertices, m.indices
+}
+
+func (m *TranslucentMesher) String() string {
+	return fmt.Sprintf("Vertices: %v\nIndices: %v", m.vertices, m.indices)
+}
+
+func (m *TranslucentMesher) Clone() Mesher {
+	return NewTranslucentMesher()
+}
+
+func (m *TranslucentMesher) setupBuffers() {
+	m.uploadIndexed(m.vertices, m.indices, m.VertexLayout(), gl.DYNAMIC_DRAW)
+}
+
+// splitPasses returns the full opaque cubes that go to the main mesher, and
+// whether any cubes need the model or translucent passes.
+func splitPasses(cubes []primitive.Cube) (opaque []primitive.Cube, hasModels, hasTranslucent bool) {
+	for _, cube := range cubes {
+		switch {
+		case cube.Translucent:
+			hasTranslucent = true
+		case !cube.IsFull():
+			hasModels = true
+		default:
+			opaque = append(opaque, cube)
+		}
+	}
+	return opaque, hasModels, hasTranslucent
+}
@@ -0,0 +1,78 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func translucentCube(x float32, color component.Color, opacity float32) primitive.Cube {
+	cube := primitive.Cube{Size: 1, Color: color, Translucent: true, Opacity: opacity}
+	cube.X = x
+	return cube
+}
+
+func TestTranslucentMesherHidesSharedFaces(t *testing.T) {
+	blue := component.Color{0.2, 0.4, 0.9}
+	water := translucentCube(0, blue, 0.5)
+	stone := primitive.Cube{Size: 1}
+	stone.X = 1
+	slab := primitive.Cube{Size: 1, Model: primitive.SlabModel}
+	slab.X = 1
+
+	tests := []struct {
+		name  string
+		cubes []primitive.Cube
+		want  int
+	}{
+		{"alone", []primitive.Cube{water}, 6},
+		{"same block", []primitive.Cube{water, translucentCube(1, blue, 0.5)}, 10},
+		{"default opacity matches", []primitive.Cube{translucentCube(0, blue, 0), translucentCube(1, blue, defaultOpacity)}, 10},
+		{"different colour", []primitive.Cube{water, translucentCube(1, component.Color{0.9, 0.2, 0.2}, 0.5)}, 12},
+		{"different opacity", []primitive.Cube{water, translucentCube(1, blue, 0.8)}, 12},
+		{"against a full block", []primitive.Cube{water, stone}, 5},
+		{"against a slab", []primitive.Cube{water, slab}, 6},
+	}
+	for _, tt := range tests {
+		m := NewTranslucentMesher()
+		m.GenerateMesh(tt.cubes)
+		if got := len(m.quads); got != tt.want {
+			t.Errorf("%s: %d quads, want %d", tt.name, got, tt.want)
+		}
+		if got := len(m.indices); got != tt.want*6 {
+			t.Errorf("%s: %d indices, want %d", tt.name, got, tt.want*6)
+		}
+	}
+}
+
+func TestTranslucentMesherSortsBackToFront(t *testing.T) {
+	var cubes []primitive.Cube
+	for x := 0; x < 4; x++ {
+		// Alternate colours so every shared face is kept.
+		color := component.Color{0.2, 0.4, 0.9}
+		if x%2 == 1 {
+			color = component.Color{0.9, 0.4, 0.2}
+		}
+		cubes = append(cubes, translucentCube(float32(x), color, 0.5))
+	}
+	m := NewTranslucentMesher()
+	m.GenerateMesh(cubes)
+
+	for _, eye := range []mgl32.Vec3{{-5, 0.5, 0.5}, {10, 3, -2}, {2, 0.5, 8}} {
+		m.Sort(eye)
+		last := float32(-1)
+		for i := 0; i < len(m.indices); i += 6 {
+			quad := m.quads[m.indices[i]/4]
+			d := quad.center.Sub(eye).LenSqr()
+			if last >= 0 && d > last {
+				t.Fatalf("eye %v: quad at %v is drawn after a nearer one", eye, quad.center)
+			}
+			last = d
+		}
+		if len(m.indices) != len(m.quads)*6 {
+			t.Fatalf("eye %v: sort left %d indices for %d quads", eye, len(m.indices), len(m.quads))
+		}
+	}
+}
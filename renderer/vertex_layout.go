@@ -0,0 +1,110 @@
+package renderer
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// VertexAttribute describes one attribute of an interleaved vertex buffer.
+// Name identifies the attribute for code that reads meshes on the CPU.
+// Integer attributes reach the shader as ints instead of being converted to
+// floats. A non-zero Divisor makes the attribute advance per instance.
+type VertexAttribute struct {
+	Name       string
+	Location   uint32
+	Size       int32
+	Type       uint32
+	Normalized bool
+	Integer    bool
+	Offset     int
+	Divisor    uint32
+}
+
+// VertexLayout describes how a mesher interleaves its vertex data. Stride
+// is in bytes.
+type VertexLayout struct {
+	Stride     int32
+	Attributes []VertexAttribute
+}
+
+var (
+	PositionColorLayout = VertexLayout{
+		Stride: 6 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "color", Location: 1, Size: 3, Type: gl.FLOAT, Offset: 3 * 4},
+		},
+	}
+	PositionColorNormalLayout = VertexLayout{
+		Stride: 9 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "color", Location: 1, Size: 3, Type: gl.FLOAT, Offset: 3 * 4},
+			{Name: "normal", Location: 2, Size: 3, Type: gl.FLOAT, Offset: 6 * 4},
+		},
+	}
+	// PositionColorAlphaNormalLayout feeds a vec4 colour. Shaders that read
+	// location 1 as a vec4 get an alpha of 1 from the three float layouts.
+	PositionColorAlphaNormalLayout = VertexLayout{
+		Stride: 10 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "color", Location: 1, Size: 4, Type: gl.FLOAT, Offset: 3 * 4},
+			{Name: "normal", Location: 2, Size: 3, Type: gl.FLOAT, Offset: 7 * 4},
+		},
+	}
+	// PackedLayout is two uint32s per vertex, see PackVertex.
+	PackedLayout = VertexLayout{
+		Stride: 2 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "packed0", Location: 0, Size: 1, Type: gl.UNSIGNED_INT, Integer: true, Offset: 0},
+			{Name: "packed1", Location: 1, Size: 1, Type: gl.UNSIGNED_INT, Integer: true, Offset: 4},
+		},
+	}
+	PositionUVLayerLayout = VertexLayout{
+		Stride: 6 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "uv", Location: 1, Size: 2, Type: gl.FLOAT, Offset: 3 * 4},
+			{Name: "layer", Location: 2, Size: 1, Type: gl.FLOAT, Offset: 5 * 4},
+		},
+	}
+	PositionUVLayerNormalLayout = VertexLayout{
+		Stride: 9 * 4,
+		Attributes: []VertexAttribute{
+			{Name: "position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "uv", Location: 1, Size: 2, Type: gl.FLOAT, Offset: 3 * 4},
+			{Name: "layer", Location: 2, Size: 1, Type: gl.FLOAT, Offset: 5 * 4},
+			{Name: "normal", Location: 3, Size: 3, Type: gl.FLOAT, Offset: 6 * 4},
+		},
+	}
+)
+
+// Enable points each attribute at the currently bound GL_ARRAY_BUFFER.
+func (l VertexLayout) Enable() {
+	for _, a := range l.Attributes {
+		if a.Integer {
+			gl.VertexAttribIPointerWithOffset(a.Location, a.Size, a.Type, l.Stride, uintptr(a.Offset))
+		} else {
+			gl.VertexAttribPointerWithOffset(a.Location, a.Size, a.Type, a.Normalized, l.Stride, uintptr(a.Offset))
+		}
+		gl.EnableVertexAttribArray(a.Location)
+		if a.Divisor != 0 {
+			gl.VertexAttribDivisor(a.Location, a.Divisor)
+		}
+	}
+}
+
+// Floats is the number of 32-bit values per vertex.
+func (l VertexLayout) Floats() int {
+	return int(l.Stride) / 4
+}
+
+// Attribute looks up an attribute by name.
+func (l VertexLayout) Attribute(name string) (VertexAttribute, bool) {
+	for _, a := range l.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return VertexAttribute{}, false
+}
@@ -0,0 +1,23 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform vec2 texelSize;
+// direction is (1, 0) for the horizontal pass and (0, 1) for the vertical.
+uniform vec2 direction;
+
+// A 9 tap gaussian using linear filtering to read two texels per tap.
+const float offsets[3] = float[](0.0, 1.3846153846, 3.2307692308);
+const float weights[3] = float[](0.2270270270, 0.3162162162, 0.0702702703);
+
+void main() {
+    vec2 stride = direction * texelSize;
+    vec3 color = texture(screenTexture, TexCoord).rgb * weights[0];
+    for (int i = 1; i < 3; i++) {
+        color += texture(screenTexture, TexCoord + stride * offsets[i]).rgb * weights[i];
+        color += texture(screenTexture, TexCoord - stride * offsets[i]).rgb * weights[i];
+    }
+    outputColor = vec4(color, 1.0);
+}
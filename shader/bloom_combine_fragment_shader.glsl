@@ -0,0 +1,14 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform sampler2D bloomTexture;
+uniform float intensity;
+
+void main() {
+    vec3 color = texture(screenTexture, TexCoord).rgb;
+    vec3 bloom = texture(bloomTexture, TexCoord).rgb;
+    outputColor = vec4(color + bloom * intensity, 1.0);
+}
@@ -0,0 +1,16 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform float threshold;
+
+// Keeps what is brighter than threshold, with a soft knee so bloom doesn't
+// pop in.
+void main() {
+    vec3 color = texture(screenTexture, TexCoord).rgb;
+    float brightness = max(color.r, max(color.g, color.b));
+    float knee = clamp((brightness - threshold * 0.5) / max(threshold * 0.5, 1e-4), 0.0, 1.0);
+    outputColor = vec4(color * knee * knee, 1.0);
+}
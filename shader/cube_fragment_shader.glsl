@@ -1,10 +1,13 @@
 #version 330 core
 
-in vec3 ourColor;
+in vec4 ourColor;
+in vec3 Normal;
+in vec3 FragPos;
 out vec4 outputColor;
 
-uniform sampler2D ourTexture;
+#include "lighting.glsl"
+#include "fog.glsl"
 
 void main() {
-    outputColor = vec4(ourColor, 1.0);
+    outputColor = vec4(applyFog(ourColor.rgb * lighting(Normal, FragPos), FragPos), ourColor.a);
 }
@@ -1,9 +1,12 @@
 #version 330 core
 
 layout(location = 0) in vec3 aPos;
-layout(location = 1) in vec3 aColor;
+layout(location = 1) in vec4 aColor;
+layout(location = 2) in vec3 aNormal;
 
-out vec3 ourColor;
+out vec4 ourColor;
+out vec3 Normal;
+out vec3 FragPos;
 
 uniform mat4 model;
 uniform mat4 view;
@@ -11,7 +14,9 @@ uniform mat4 projection;
 
 void main()
 {
-    gl_Position = projection * view * vec4(aPos, 1.0);
+    vec4 worldPos = model * vec4(aPos, 1.0);
+    gl_Position = projection * view * worldPos;
+    FragPos = worldPos.xyz;
     ourColor = aColor;
+    Normal = aNormal;
 }
-
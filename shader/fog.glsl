@@ -0,0 +1,27 @@
+// Distance fog shared by the lit fragment shaders. Set from renderer.Fog;
+// fogMode matches renderer.FogMode.
+uniform int fogMode;
+uniform float fogStart;
+uniform float fogEnd;
+uniform float fogDensity;
+uniform vec3 fogColor;
+uniform vec3 cameraPosition;
+
+float fogAmount(float distance) {
+    if (fogMode == 1) {
+        if (fogEnd <= fogStart) {
+            return 0.0;
+        }
+        return clamp((distance - fogStart) / (fogEnd - fogStart), 0.0, 1.0);
+    }
+    if (fogMode == 2) {
+        return clamp(1.0 - exp(-fogDensity * distance), 0.0, 1.0);
+    }
+    return 0.0;
+}
+
+// applyFog blends color towards the fog colour by fragPos's distance from
+// the camera.
+vec3 applyFog(vec3 color, vec3 fragPos) {
+    return mix(color, fogColor, fogAmount(length(fragPos - cameraPosition)));
+}
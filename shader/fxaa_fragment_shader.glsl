@@ -0,0 +1,51 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform vec2 texelSize;
+
+// A compact FXAA after Timothy Lottes: blur along edges found from luma,
+// falling back to a narrower blur when the wide one crosses another edge.
+const float spanMax = 8.0;
+const float reduceMul = 1.0 / 8.0;
+const float reduceMin = 1.0 / 128.0;
+
+float luma(vec3 c) {
+    return dot(c, vec3(0.299, 0.587, 0.114));
+}
+
+void main() {
+    vec3 rgbM = texture(screenTexture, TexCoord).rgb;
+    float lumaNW = luma(texture(screenTexture, TexCoord + vec2(-1.0, -1.0) * texelSize).rgb);
+    float lumaNE = luma(texture(screenTexture, TexCoord + vec2(1.0, -1.0) * texelSize).rgb);
+    float lumaSW = luma(texture(screenTexture, TexCoord + vec2(-1.0, 1.0) * texelSize).rgb);
+    float lumaSE = luma(texture(screenTexture, TexCoord + vec2(1.0, 1.0) * texelSize).rgb);
+    float lumaM = luma(rgbM);
+
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    vec2 dir = vec2(
+        -((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+        (lumaNW + lumaSW) - (lumaNE + lumaSE)
+    );
+    float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * reduceMul, reduceMin);
+    float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+    dir = clamp(dir * rcpDirMin, vec2(-spanMax), vec2(spanMax)) * texelSize;
+
+    vec3 rgbA = 0.5 * (
+        texture(screenTexture, TexCoord + dir * (1.0 / 3.0 - 0.5)).rgb +
+        texture(screenTexture, TexCoord + dir * (2.0 / 3.0 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (
+        texture(screenTexture, TexCoord + dir * -0.5).rgb +
+        texture(screenTexture, TexCoord + dir * 0.5).rgb);
+
+    float lumaB = luma(rgbB);
+    if (lumaB < lumaMin || lumaB > lumaMax) {
+        outputColor = vec4(rgbA, 1.0);
+    } else {
+        outputColor = vec4(rgbB, 1.0);
+    }
+}
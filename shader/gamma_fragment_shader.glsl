@@ -0,0 +1,12 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform float gamma;
+
+void main() {
+    vec3 color = texture(screenTexture, TexCoord).rgb;
+    outputColor = vec4(pow(max(color, 0.0), vec3(1.0 / gamma)), 1.0);
+}
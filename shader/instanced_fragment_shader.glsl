@@ -0,0 +1,22 @@
+#version 330 core
+
+in vec2 TexCoord;
+in vec3 Normal;
+in vec3 FragPos;
+in vec3 ourColor;
+flat in float Layer;
+out vec4 outputColor;
+
+uniform sampler2DArray textureArray;
+uniform bool textured;
+
+#include "lighting.glsl"
+#include "fog.glsl"
+
+void main() {
+    vec3 color = ourColor;
+    if (textured && Layer >= 0.0) {
+        color *= texture(textureArray, vec3(TexCoord, Layer)).rgb;
+    }
+    outputColor = vec4(applyFog(color * lighting(Normal, FragPos), FragPos), 1.0);
+}
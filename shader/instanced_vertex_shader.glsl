@@ -0,0 +1,28 @@
+#version 330 core
+
+layout(location = 0) in vec3 aPos;
+layout(location = 1) in vec2 aTexCoord;
+layout(location = 2) in vec3 aNormal;
+layout(location = 3) in mat4 aModel;
+layout(location = 7) in vec3 aColor;
+layout(location = 8) in float aLayer;
+
+out vec2 TexCoord;
+out vec3 Normal;
+out vec3 FragPos;
+out vec3 ourColor;
+flat out float Layer;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+void main()
+{
+    vec4 worldPos = aModel * vec4(aPos, 1.0);
+    gl_Position = projection * view * worldPos;
+    FragPos = worldPos.xyz;
+    TexCoord = aTexCoord;
+    Normal = mat3(aModel) * aNormal;
+    ourColor = aColor;
+    Layer = aLayer;
+}
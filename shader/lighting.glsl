@@ -0,0 +1,72 @@
+// Sun, ambient, point lights and sun shadows shared by the lit fragment
+// shaders. Set from renderer.Lighting and renderer.ShadowSettings.
+#define MAX_POINT_LIGHTS 8
+
+uniform vec3 lightDirection;
+uniform vec3 sunColor;
+uniform float ambient;
+uniform int pointLightCount;
+uniform vec3 pointLightPositions[MAX_POINT_LIGHTS];
+uniform vec3 pointLightColors[MAX_POINT_LIGHTS];
+uniform float pointLightRadii[MAX_POINT_LIGHTS];
+
+uniform bool shadowsEnabled;
+uniform sampler2D shadowMap;
+uniform mat4 lightSpace;
+uniform float shadowBias;
+uniform int shadowPCF;
+
+// sunShadow returns how much of the sun is blocked at fragPos, averaging
+// depth comparisons over a square of (2*shadowPCF+1)^2 texels.
+float sunShadow(vec3 n, vec3 fragPos) {
+    if (!shadowsEnabled) {
+        return 0.0;
+    }
+    vec4 lightPos = lightSpace * vec4(fragPos, 1.0);
+    vec3 coords = lightPos.xyz / lightPos.w * 0.5 + 0.5;
+    if (coords.z > 1.0) {
+        return 0.0;
+    }
+
+    // Surfaces at a grazing angle to the sun need more bias.
+    float slope = 1.0 - max(dot(n, normalize(-lightDirection)), 0.0);
+    float bias = shadowBias * (1.0 + 4.0 * slope);
+
+    vec2 texel = 1.0 / vec2(textureSize(shadowMap, 0));
+    float blocked = 0.0;
+    for (int x = -shadowPCF; x <= shadowPCF; x++) {
+        for (int y = -shadowPCF; y <= shadowPCF; y++) {
+            float depth = texture(shadowMap, coords.xy + vec2(x, y) * texel).r;
+            blocked += coords.z - bias > depth ? 1.0 : 0.0;
+        }
+    }
+    float samples = float((2 * shadowPCF + 1) * (2 * shadowPCF + 1));
+    return blocked / samples;
+}
+
+// lighting returns the light reaching a surface. Surfaces without a normal
+// only get ambient light.
+vec3 lighting(vec3 normal, vec3 fragPos) {
+    vec3 light = vec3(ambient);
+    if (length(normal) == 0.0) {
+        return light;
+    }
+    vec3 n = normalize(normal);
+
+    float diffuse = max(dot(n, normalize(-lightDirection)), 0.0);
+    if (diffuse > 0.0) {
+        diffuse *= 1.0 - sunShadow(n, fragPos);
+    }
+    light += (1.0 - ambient) * diffuse * sunColor;
+
+    for (int i = 0; i < pointLightCount; i++) {
+        vec3 toLight = pointLightPositions[i] - fragPos;
+        float distance = length(toLight);
+        if (distance >= pointLightRadii[i] || distance == 0.0) {
+            continue;
+        }
+        float falloff = 1.0 - distance / pointLightRadii[i];
+        light += max(dot(n, toLight / distance), 0.0) * falloff * falloff * pointLightColors[i];
+    }
+    return light;
+}
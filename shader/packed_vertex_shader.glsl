@@ -0,0 +1,43 @@
+#version 330 core
+
+layout(location = 0) in uint aPacked0;
+layout(location = 1) in uint aPacked1;
+
+out vec4 ourColor;
+out vec3 Normal;
+out vec3 FragPos;
+
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+
+// Same order as packedNormals in renderer/packed.go.
+const vec3 normals[6] = vec3[6](
+    vec3(1, 0, 0), vec3(-1, 0, 0),
+    vec3(0, 1, 0), vec3(0, -1, 0),
+    vec3(0, 0, 1), vec3(0, 0, -1)
+);
+
+void main()
+{
+    vec3 position = vec3(
+        float(aPacked0 & 63u),
+        float((aPacked0 >> 6) & 63u),
+        float((aPacked0 >> 12) & 63u)
+    );
+    uint normal = (aPacked0 >> 18) & 7u;
+    float ao = float((aPacked0 >> 21) & 3u) / 3.0;
+    float light = float((aPacked0 >> 23) & 15u) / 15.0;
+
+    vec3 color = vec3(
+        float(aPacked1 & 255u),
+        float((aPacked1 >> 8) & 255u),
+        float((aPacked1 >> 16) & 255u)
+    ) / 255.0;
+
+    vec4 worldPos = model * vec4(position, 1.0);
+    gl_Position = projection * view * worldPos;
+    FragPos = worldPos.xyz;
+    ourColor = vec4(color * ao * light, 1.0);
+    Normal = normals[min(normal, 5u)];
+}
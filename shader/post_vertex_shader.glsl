@@ -0,0 +1,12 @@
+#version 330 core
+
+// A triangle covering the screen, drawn without vertex buffers, for
+// post-processing passes.
+out vec2 TexCoord;
+
+void main()
+{
+    vec2 ndc = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2) * 2.0 - 1.0;
+    TexCoord = ndc * 0.5 + 0.5;
+    gl_Position = vec4(ndc, 0.0, 1.0);
+}
@@ -0,0 +1,9 @@
+#version 330 core
+
+out vec4 outputColor;
+
+uniform vec4 color;
+
+void main() {
+    outputColor = color;
+}
@@ -3,6 +3,7 @@ package shader
 import (
 	"embed"
 	"fmt"
+	"strings"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -10,6 +11,49 @@ import (
 //go:embed *.glsl
 var ShaderFS embed.FS
 
+// NewProgramFromFiles compiles a program from two files in ShaderFS.
+func NewProgramFromFiles(vertexShaderFile, fragmentShaderFile string) (uint32, error) {
+	vertexShaderSource, err := ShaderFS.ReadFile(vertexShaderFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read vertex shader: %v", err)
+	}
+
+	fragmentShaderSource, err := ShaderFS.ReadFile(fragmentShaderFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read fragment shader: %v", err)
+	}
+
+	vertex, err := resolveIncludes(string(vertexShaderSource))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", vertexShaderFile, err)
+	}
+	fragment, err := resolveIncludes(string(fragmentShaderSource))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", fragmentShaderFile, err)
+	}
+
+	return NewProgram(vertex+"\x00", fragment+"\x00")
+}
+
+// resolveIncludes replaces lines of the form #include "file.glsl" with that
+// file from ShaderFS. Included files can't include others.
+func resolveIncludes(source string) (string, error) {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#include") {
+			continue
+		}
+		name := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "#include")), `"`)
+		included, err := ShaderFS.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to include %q: %v", name, err)
+		}
+		lines[i] = string(included)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func NewProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
 	vertexShader, err := CompileShader(vertexShaderSource, gl.VERTEX_SHADER)
 	if err != nil {
@@ -0,0 +1,31 @@
+package shader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	files, err := ShaderFS.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		source, err := ShaderFS.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolved, err := resolveIncludes(string(source))
+		if err != nil {
+			t.Errorf("%s: %v", f.Name(), err)
+			continue
+		}
+		if strings.Contains(resolved, "#include") {
+			t.Errorf("%s: unresolved include", f.Name())
+		}
+	}
+
+	if _, err := resolveIncludes(`#include "missing.glsl"`); err == nil {
+		t.Error("missing include should fail")
+	}
+}
@@ -0,0 +1,6 @@
+#version 330 core
+
+// Depth only; the shadow pass has no colour attachment.
+void main()
+{
+}
@@ -0,0 +1,17 @@
+#version 330 core
+
+layout(location = 0) in uint aPacked0;
+
+uniform mat4 model;
+uniform mat4 lightSpace;
+
+// Position unpacking matches packed_vertex_shader.glsl.
+void main()
+{
+    vec3 position = vec3(
+        float(aPacked0 & 63u),
+        float((aPacked0 >> 6) & 63u),
+        float((aPacked0 >> 12) & 63u)
+    );
+    gl_Position = lightSpace * model * vec4(position, 1.0);
+}
@@ -0,0 +1,40 @@
+#version 330 core
+
+in vec2 ndc;
+out vec4 outputColor;
+
+// inverseViewProjection undoes the camera's rotation and projection but not
+// its position, so the sky never gets closer.
+uniform mat4 inverseViewProjection;
+uniform vec3 horizonColor;
+uniform vec3 zenithColor;
+// sunDirection points towards the sun. It is zero when there is no sun.
+uniform vec3 sunDirection;
+uniform float stars;
+
+const vec3 sunDiscColor = vec3(1.0, 0.95, 0.8);
+const vec3 moonDiscColor = vec3(0.85, 0.88, 0.95);
+
+// starField lights about one in 300 cells of a grid over the sky.
+float starField(vec3 d) {
+    vec3 cell = floor(d * 150.0);
+    float h = fract(sin(dot(cell, vec3(12.9898, 78.233, 37.719))) * 43758.5453);
+    return step(0.997, h);
+}
+
+void main() {
+    vec4 far = inverseViewProjection * vec4(ndc, 1.0, 1.0);
+    vec3 direction = normalize(far.xyz / far.w);
+    float t = sqrt(max(direction.y, 0.0));
+    vec3 color = mix(horizonColor, zenithColor, t);
+
+    if (stars > 0.0 && direction.y > 0.0) {
+        color += vec3(starField(direction) * stars);
+    }
+    if (length(sunDirection) > 0.0) {
+        float sun = dot(direction, normalize(sunDirection));
+        color = mix(color, sunDiscColor, smoothstep(0.9985, 0.999, sun));
+        color = mix(color, moonDiscColor, smoothstep(0.9993, 0.9996, -sun) * stars);
+    }
+    outputColor = vec4(color, 1.0);
+}
@@ -0,0 +1,10 @@
+#version 330 core
+
+// A triangle covering the screen, drawn without vertex buffers.
+out vec2 ndc;
+
+void main()
+{
+    ndc = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2) * 2.0 - 1.0;
+    gl_Position = vec4(ndc, 1.0, 1.0);
+}
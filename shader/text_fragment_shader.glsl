@@ -0,0 +1,14 @@
+#version 330 core
+
+in vec2 TexCoord;
+in vec4 ourColor;
+out vec4 outputColor;
+
+// The font atlas keeps glyph coverage in the red channel.
+uniform sampler2D fontAtlas;
+
+void main() {
+    // Negative texture coordinates mark solid rectangles.
+    float coverage = TexCoord.x < 0.0 ? 1.0 : texture(fontAtlas, TexCoord).r;
+    outputColor = vec4(ourColor.rgb, ourColor.a * coverage);
+}
@@ -0,0 +1,17 @@
+#version 330 core
+
+in vec2 TexCoord;
+flat in float Layer;
+in vec3 Normal;
+in vec3 FragPos;
+out vec4 outputColor;
+
+uniform sampler2DArray textureArray;
+
+#include "lighting.glsl"
+#include "fog.glsl"
+
+void main() {
+    vec4 color = texture(textureArray, vec3(TexCoord, Layer));
+    outputColor = vec4(applyFog(color.rgb * lighting(Normal, FragPos), FragPos), color.a);
+}
@@ -0,0 +1,26 @@
+#version 330 core
+
+layout(location = 0) in vec3 aPos;
+layout(location = 1) in vec2 aTexCoord;
+layout(location = 2) in float aLayer;
+layout(location = 3) in vec3 aNormal;
+
+out vec2 TexCoord;
+flat out float Layer;
+out vec3 Normal;
+out vec3 FragPos;
+
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+
+void main()
+{
+    vec4 worldPos = model * vec4(aPos, 1.0);
+    gl_Position = projection * view * worldPos;
+    FragPos = worldPos.xyz;
+    TexCoord = aTexCoord;
+    Layer = aLayer;
+    // Sections are only translated, so normals need no transform.
+    Normal = aNormal;
+}
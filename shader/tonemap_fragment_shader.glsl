@@ -0,0 +1,17 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+uniform float exposure;
+
+// ACES filmic curve fitted by Krzysztof Narkowicz.
+vec3 aces(vec3 x) {
+    return clamp((x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14), 0.0, 1.0);
+}
+
+void main() {
+    vec3 color = texture(screenTexture, TexCoord).rgb;
+    outputColor = vec4(aces(color * exposure), 1.0);
+}
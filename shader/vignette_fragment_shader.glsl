@@ -0,0 +1,17 @@
+#version 330 core
+
+in vec2 TexCoord;
+out vec4 outputColor;
+
+uniform sampler2D screenTexture;
+// strength is how dark the corners get; radius is where darkening starts,
+// as a distance from the centre with the corners at about 0.7.
+uniform float strength;
+uniform float radius;
+
+void main() {
+    vec3 color = texture(screenTexture, TexCoord).rgb;
+    float d = length(TexCoord - 0.5);
+    float shade = 1.0 - strength * smoothstep(radius, 0.75, d);
+    outputColor = vec4(color * shade, 1.0);
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/dfirebaugh/cube/pkg/block"
 	"github.com/dfirebaugh/cube/pkg/primitive"
 	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,7 +19,7 @@ func main() {
 	cubeRenderer := renderer.NewBlockRenderer()
 	e.AddRenderer(cubeRenderer)
 
-	chunk := primitive.NewChunk()
+	chunk := primitive.NewChunk(mgl32.Vec3{})
 
 	for x := 0; x < 16; x++ {
 		for y := 0; y < 16; y++ {
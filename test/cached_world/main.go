@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+func main() {
+	e := engine.New(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered in startup function:", r)
+			}
+		}()
+	})
+
+	cache, err := renderer.NewMeshCache(filepath.Join(os.TempDir(), "cube-mesh-cache"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewBinaryGreedyMesher())
+	meshRenderer.SetMeshCache(cache)
+	e.AddRenderer(meshRenderer)
+
+	for _, cube := range scene.Terrain(64, 1) {
+		meshRenderer.AddCube(cube)
+	}
+	e.Run()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/daycycle"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/sirupsen/logrus"
+)
+
+// A day passes every two minutes over the shapes scene. Nightfall and
+// daybreak are logged from the TimeOfDay topic.
+func main() {
+	e := engine.New(func() {})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	meshRenderer.SetShadows(renderer.DefaultShadowSettings())
+	e.AddRenderer(meshRenderer)
+	for _, cube := range scene.Shapes() {
+		meshRenderer.AddCube(cube)
+	}
+
+	e.Clock().SetDayLength(2 * time.Minute)
+	e.SetTimeOfDay(daycycle.Sunrise - 0.02)
+	e.SetDayNightCycle(true)
+
+	bus := e.MessageBus()
+	go func() {
+		msg := bus.Subscribe()
+		defer bus.Unsubscribe(msg)
+
+		night := daycycle.At(e.TimeOfDay()).IsNight()
+		for m := range msg {
+			state, ok := m.GetPayload().(daycycle.State)
+			if !ok || m.GetTopic() != "TimeOfDay" || state.IsNight() == night {
+				continue
+			}
+			night = state.IsNight()
+			if night {
+				logrus.Infof("night falls at %.1fh", daycycle.Hour(state.TimeOfDay))
+			} else {
+				logrus.Infof("day breaks at %.1fh", daycycle.Hour(state.TimeOfDay))
+			}
+		}
+	}()
+
+	e.Run()
+}
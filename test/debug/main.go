@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The shapes scene with its collision boxes, the world axes and a ray
+// sweeping around it that leaves a fading trail. Press F5 for section
+// borders.
+func main() {
+	e := engine.New(func() {})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	e.AddRenderer(meshRenderer)
+	cubes := scene.Shapes()
+	for _, cube := range cubes {
+		meshRenderer.AddCube(cube)
+	}
+
+	draw := e.DebugDraw()
+	go func() {
+		start := time.Now()
+		for range time.Tick(16 * time.Millisecond) {
+			draw.DrawAxis(mgl32.Vec3{}, 2, 0)
+			for _, cube := range cubes {
+				for _, box := range cube.CollisionBoxes() {
+					draw.DrawAABB(box, component.Color{0, 1, 1}, 0)
+				}
+			}
+
+			a := time.Since(start).Seconds()
+			direction := mgl32.Vec3{float32(math.Cos(a)), -0.3, float32(math.Sin(a))}
+			draw.DrawRay(mgl32.Vec3{6, 6, 4}, direction, 8, component.Color{1, 0, 1}, 500*time.Millisecond)
+		}
+	}()
+
+	e.Run()
+}
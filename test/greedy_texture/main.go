@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/block"
+	"github.com/dfirebaugh/cube/pkg/primitive"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+const (
+	cubeSize = 10
+)
+
+func main() {
+	e := engine.New(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered in startup function:", r)
+			}
+		}()
+	})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewTextureGreedyMesher(block.TextureArray()))
+	e.AddRenderer(meshRenderer)
+
+	for x := 0; x < cubeSize; x++ {
+		for y := 0; y < cubeSize; y++ {
+			for z := 0; z < cubeSize; z++ {
+				var cube primitive.Cube
+				switch {
+				case y == cubeSize-1:
+					cube = block.GreenBlock()
+				case y > cubeSize/2:
+					cube = block.YellowBlock()
+				case x < cubeSize/2:
+					cube = block.GreyBlock()
+				default:
+					cube = block.TestBlock()
+				}
+				cube.X = float32(x)
+				cube.Y = float32(y)
+				cube.Z = float32(z)
+				meshRenderer.AddCube(cube)
+			}
+		}
+	}
+
+	e.Run()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/message"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Two coloured point lights circle the shapes scene under a dim sun that
+// casts shadows. The lights are driven over the message bus.
+func main() {
+	e := engine.New(func() {})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	meshRenderer.SetShadows(renderer.DefaultShadowSettings())
+	e.AddRenderer(meshRenderer)
+	for _, cube := range scene.Shapes() {
+		meshRenderer.AddCube(cube)
+	}
+
+	bus := e.MessageBus()
+	bus.Publish(message.Message{Topic: "SetAmbient", Requestor: "lights", Payload: float32(0.15)})
+	bus.Publish(message.Message{Topic: "SetSunColor", Requestor: "lights", Payload: component.Color{0.4, 0.4, 0.5}})
+
+	go func() {
+		start := time.Now()
+		for range time.Tick(16 * time.Millisecond) {
+			t := float32(time.Since(start).Seconds())
+			orbit := func(offset float32) mgl32.Vec3 {
+				a := float64(t + offset)
+				return mgl32.Vec3{6 + 5*float32(math.Cos(a)), 3, 4 + 5*float32(math.Sin(a))}
+			}
+			bus.Publish(message.Message{
+				Topic:     "SetPointLights",
+				Requestor: "lights",
+				Payload: []renderer.PointLight{
+					{Position: orbit(0), Color: component.Color{1, 0.5, 0.2}, Radius: 8},
+					{Position: orbit(math.Pi), Color: component.Color{0.2, 0.5, 1}, Radius: 8},
+				},
+			})
+		}
+	}()
+
+	e.Run()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const particleCount = 20000
+
+type particle struct {
+	velocity mgl32.Vec3
+	spin     mgl32.Vec3
+}
+
+func main() {
+	e := engine.New(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered in startup function:", r)
+			}
+		}()
+	})
+
+	instanced := renderer.NewInstancedBlockRenderer(0)
+	e.AddRenderer(instanced)
+
+	particles := make([]particle, particleCount)
+	instances := make([]renderer.Instance, particleCount)
+	for i := range instances {
+		instances[i] = spawn(&particles[i])
+	}
+	instanced.SetInstances(instances)
+
+	instanced.OnUpdate(func(dt float32, instances []renderer.Instance) []renderer.Instance {
+		for i := range instances {
+			p := &particles[i]
+			p.velocity[1] -= 9.8 * dt
+			instances[i].Position = instances[i].Position.Add(p.velocity.Mul(dt))
+			angle := p.spin.Len() * dt
+			if angle > 0 {
+				instances[i].Rotation = mgl32.QuatRotate(angle, p.spin.Normalize()).Mul(instances[i].Rotation)
+			}
+			if instances[i].Position[1] < -20 {
+				instances[i] = spawn(p)
+			}
+		}
+		return instances
+	})
+
+	e.Run()
+}
+
+func spawn(p *particle) renderer.Instance {
+	p.velocity = mgl32.Vec3{rand.Float32()*10 - 5, rand.Float32()*15 + 5, rand.Float32()*10 - 5}
+	p.spin = mgl32.Vec3{rand.Float32() - 0.5, rand.Float32() - 0.5, rand.Float32() - 0.5}.Mul(10)
+	return renderer.Instance{
+		Position: mgl32.Vec3{0, 0, 0},
+		Rotation: mgl32.QuatIdent(),
+		Scale:    0.2,
+		Color:    component.Color{rand.Float32(), rand.Float32(), rand.Float32()},
+		Layer:    -1,
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/component"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The shapes scene through the default post-processing chain. A bright
+// point light pushes colours past 1 so bloom and tone mapping show.
+func main() {
+	e := engine.New(func() {})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	e.AddRenderer(meshRenderer)
+	for _, cube := range scene.Shapes() {
+		meshRenderer.AddCube(cube)
+	}
+	meshRenderer.SetPointLights([]renderer.PointLight{
+		{Position: mgl32.Vec3{6, 4, 6}, Color: component.Color{3, 2.2, 1.2}, Radius: 10},
+	})
+
+	post := renderer.NewPostProcessor(renderer.DefaultPostPasses()...)
+	if vignette, ok := post.Pass("vignette"); ok {
+		vignette.(*renderer.ShaderPass).Set("strength", 0.5)
+	}
+	e.SetPostProcessor(post)
+
+	e.Run()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+// Look around the shapes scene to outline the block at the centre of the
+// screen. F6 turns the outline off and on.
+func main() {
+	e := engine.New(func() {})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	e.AddRenderer(meshRenderer)
+	for _, cube := range scene.Shapes() {
+		meshRenderer.AddCube(cube)
+	}
+	e.AddRenderer(renderer.NewSelectionRenderer(meshRenderer))
+
+	e.Run()
+}
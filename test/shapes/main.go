@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+
+	"github.com/dfirebaugh/cube/engine"
+	"github.com/dfirebaugh/cube/pkg/scene"
+	"github.com/dfirebaugh/cube/renderer"
+)
+
+func main() {
+	e := engine.New(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered in startup function:", r)
+			}
+		}()
+	})
+
+	meshRenderer := renderer.NewMeshRenderer(renderer.NewGreedyMesher())
+	e.AddRenderer(meshRenderer)
+
+	for _, cube := range scene.Shapes() {
+		meshRenderer.AddCube(cube)
+	}
+	// Fly into the shapes to slide along their collision boxes.
+	e.SetCollision(meshRenderer)
+	e.Run()
+}